@@ -0,0 +1,143 @@
+package pr
+
+import (
+	"fmt"
+)
+
+/*
+	A GaussianAccumulator estimates the means and the covariance matrices of a
+	GaussianClassifier from a stream of labeled features, one Observe at a time.
+
+	With a positive Decay, older features are exponentially forgotten: every
+	Observe of a label multiplies the accumulated weight of that label's earlier
+	features by 1-Decay before adding the new feature with weight 1. The total
+	weight of a label then approaches 1/Decay, i.e. the estimates effectively
+	cover about the last 1/Decay features of the label (e.g. 100 for a Decay of
+	0.01). A zero Decay gives the plain running estimates, the same as
+	TrainGaussian on all observed features.
+*/
+type GaussianAccumulator struct {
+	// the forgetting factor in [0, 1)
+	Decay float64
+
+	dim int
+	// the total weight of the features of each label
+	weights []float64
+	// the weighted means of each label
+	means [][]float64
+	// the weighted sums of the outer products of the deviations of each label,
+	// dim*dim
+	m2s [][]float64
+}
+
+/*
+	NewGaussianAccumulator returns a *GaussianAccumulator for lblCnt labels and
+	features of dimension dim. An error is returned if decay is not in [0, 1).
+*/
+func NewGaussianAccumulator(lblCnt, dim int, decay float64) (*GaussianAccumulator, error) {
+	if !(decay >= 0 && decay < 1) {
+		return nil, fmt.Errorf("decay is %v, expected in [0, 1)", decay)
+	}
+	ga := &GaussianAccumulator{
+		Decay:   decay,
+		dim:     dim,
+		weights: make([]float64, lblCnt),
+		means:   make([][]float64, lblCnt),
+		m2s:     make([][]float64, lblCnt),
+	}
+	for lbl := range ga.means {
+		ga.means[lbl] = make([]float64, dim)
+		ga.m2s[lbl] = make([]float64, dim*dim)
+	}
+	return ga, nil
+}
+
+// grow adds labels so that there are at least lblCnt labels.
+func (ga *GaussianAccumulator) grow(lblCnt int) {
+	for len(ga.weights) < lblCnt {
+		ga.weights = append(ga.weights, 0)
+		ga.means = append(ga.means, make([]float64, ga.dim))
+		ga.m2s = append(ga.m2s, make([]float64, ga.dim*ga.dim))
+	}
+}
+
+/*
+	Observe adds a feature x of the label to the running estimates.
+*/
+func (ga *GaussianAccumulator) Observe(label int, x []float64) error {
+	if label < 0 || label >= len(ga.weights) {
+		return fmt.Errorf("label %d out of range [0, %d)", label, len(ga.weights))
+	}
+	if len(x) != ga.dim {
+		return fmt.Errorf("feature has dimension %d, expected %d", len(x), ga.dim)
+	}
+
+	mean, m2 := ga.means[label], ga.m2s[label]
+	if ga.Decay > 0 {
+		/* forgetting scales the weight and the scatter but not the mean */
+		ga.weights[label] *= 1 - ga.Decay
+		for i := range m2 {
+			m2[i] *= 1 - ga.Decay
+		}
+	}
+	ga.weights[label]++
+	w := ga.weights[label]
+
+	/* weighted Welford's update */
+	delta := make([]float64, ga.dim)
+	for k := range x {
+		delta[k] = x[k] - mean[k]
+		mean[k] += delta[k] / w
+	}
+	for i := 0; i < ga.dim; i++ {
+		for j := 0; j < ga.dim; j++ {
+			m2[i*ga.dim+j] += delta[i] * (x[j] - mean[j])
+		}
+	}
+	return nil
+}
+
+/*
+	Weight returns the total weight of the features of the label, which equals
+	the number of observed features if Decay is zero.
+*/
+func (ga *GaussianAccumulator) Weight(label int) float64 {
+	return ga.weights[label]
+}
+
+/*
+	Classifier returns a *GaussianClassifier with the current estimates. An
+	error is returned if a label has a total weight of at most 1 or a singular
+	covariance matrix.
+*/
+func (ga *GaussianAccumulator) Classifier() (*GaussianClassifier, error) {
+	lblCnt := len(ga.weights)
+	clsfr := &GaussianClassifier{
+		Means:    make([][]float64, lblCnt),
+		Precs:    make([][]float64, lblCnt),
+		LogCoefs: make([]float64, lblCnt),
+	}
+
+	sigma := make([]float64, ga.dim*ga.dim)
+	for lbl := range clsfr.Means {
+		w := ga.weights[lbl]
+		if w <= 1 {
+			return nil, fmt.Errorf("label %d has a total weight of %g, more than 1 is needed", lbl, w)
+		}
+		for i, v := range ga.m2s[lbl] {
+			sigma[i] = v / (w - 1)
+		}
+		symmetrize(sigma, ga.dim)
+
+		prec, logCoef, err := gaussianPrec(sigma, ga.dim)
+		if err != nil {
+			return nil, fmt.Errorf("label %d: covariance matrix is singular: %v", lbl, err)
+		}
+
+		clsfr.Means[lbl] = append([]float64(nil), ga.means[lbl]...)
+		clsfr.Precs[lbl] = prec
+		clsfr.LogCoefs[lbl] = logCoef
+	}
+
+	return clsfr, nil
+}
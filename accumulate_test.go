@@ -0,0 +1,19 @@
+package pr
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewGaussianAccumulatorDecay(t *testing.T) {
+	for _, decay := range []float64{0, 0.01, 0.5, 0.999} {
+		if _, err := NewGaussianAccumulator(2, 3, decay); err != nil {
+			t.Errorf("decay %v: %v", decay, err)
+		}
+	}
+	for _, decay := range []float64{-0.1, 1, 1.5, math.NaN(), math.Inf(1)} {
+		if _, err := NewGaussianAccumulator(2, 3, decay); err == nil {
+			t.Errorf("decay %v: expected an error", decay)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package pr
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+	AnomalyModel fits a single multivariate Gaussian distribution on data, which
+	is assumed to contain normal samples only. The returned *GaussianClassifier
+	has one label, 0, and is meant to be used with IsAnomaly.
+*/
+func AnomalyModel(data [][]float64) (*GaussianClassifier, error) {
+	if len(data) < 2 {
+		return nil, errors.New("at least two samples are needed")
+	}
+	dim := len(data[0])
+	for i, x := range data {
+		if len(x) != dim {
+			return nil, fmt.Errorf("sample %d has dimension %d, expected %d", i, len(x), dim)
+		}
+	}
+
+	gc := GaussianTrain(&SliceFeatureSet{FeatureDim: dim, Features: [][][]float64{data}})
+	if gc == nil {
+		return nil, errors.New("covariance matrix is singular")
+	}
+	return gc, nil
+}
+
+/*
+	IsAnomaly returns true if the logarithm of the density of x on label 0 is
+	below threshold.
+
+	A threshold is usually picked from the densities of a validation set of
+	normal samples: compute gc.LogLikelyhood(0, x) for every validation sample,
+	sort them, and take the value at the quantile of the acceptable false alarm
+	rate, e.g. the 1st percentile flags about 1% of normal samples.
+*/
+func (gc *GaussianClassifier) IsAnomaly(x []float64, threshold float64) bool {
+	return gc.LogLikelyhood(0, x) < threshold
+}
@@ -0,0 +1,61 @@
+package pr
+
+import (
+	"math/rand"
+)
+
+// Modes of Balance.
+const (
+	// duplicate random features of minority labels
+	Oversample = "oversample"
+	// keep a random subset of the features of majority labels
+	Undersample = "undersample"
+)
+
+/*
+	Balance returns a view of lfs with the same number of features for every
+	label. With mode Oversample, every label is filled up to the largest label
+	count by duplicating its features chosen at random; with mode Undersample,
+	every label keeps a random subset, without replacement, of the size of the
+	smallest label count. Labels without features stay empty when oversampling.
+
+	No feature is copied: the view refers to the features of lfs by index.
+	nil is returned for an unknown mode.
+*/
+func Balance(lfs LabeledFeatureSet, mode string, seed int64) LabeledFeatureSet {
+	rng := rand.New(rand.NewSource(seed))
+
+	lblCnt := lfs.LabelCount()
+	minCnt, maxCnt := 0, 0
+	for lbl := 0; lbl < lblCnt; lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		if lbl == 0 || cnt < minCnt {
+			minCnt = cnt
+		}
+		if cnt > maxCnt {
+			maxCnt = cnt
+		}
+	}
+
+	indices := make([][]int, lblCnt)
+	for lbl := range indices {
+		cnt := lfs.FeatureCount(lbl)
+		switch mode {
+		case Oversample:
+			idx := make([]int, cnt, maxCnt)
+			for i := range idx {
+				idx[i] = i
+			}
+			for cnt > 0 && len(idx) < maxCnt {
+				idx = append(idx, rng.Intn(cnt))
+			}
+			indices[lbl] = idx
+		case Undersample:
+			indices[lbl] = rng.Perm(cnt)[:minCnt]
+		default:
+			return nil
+		}
+	}
+
+	return &subsetSet{lfs, indices}
+}
@@ -0,0 +1,82 @@
+package pr
+
+import (
+	"sort"
+	"time"
+)
+
+/*
+	BenchResult is the result of a trainer in Benchmark.
+*/
+type BenchResult struct {
+	// the mean accuracy over folds
+	Accuracy float64
+	// the mean training time of a fold
+	TrainTime time.Duration
+	// the mean time of classifying one feature
+	PredictTime time.Duration
+	// non-nil if the cross-validation failed, e.g. training failed
+	Err error
+}
+
+// timedTrainer measures the time spent in training and in the Classify
+// calls of the trained classifiers.
+type timedTrainer struct {
+	Trainer
+	trainTime   time.Duration
+	predictTime time.Duration
+	predictions int
+}
+
+type timedClassifier struct {
+	Classifier
+	tt *timedTrainer
+}
+
+// Implementation of Classifier.Classify
+func (tc timedClassifier) Classify(x []float64) int {
+	start := time.Now()
+	lbl := tc.Classifier.Classify(x)
+	tc.tt.predictTime += time.Since(start)
+	tc.tt.predictions++
+	return lbl
+}
+
+// Implementation of Trainer.Train
+func (tt *timedTrainer) Train(lfs LabeledFeatureSet) Classifier {
+	start := time.Now()
+	c := tt.Trainer.Train(lfs)
+	tt.trainTime += time.Since(start)
+	if c == nil {
+		return nil
+	}
+	return timedClassifier{c, tt}
+}
+
+/*
+	Benchmark cross-validates every trainer on lfs with folds folds, the same
+	split for all trainers, and returns the results keyed by the names of the
+	trainers. The trainers run one by one in the order of their names.
+*/
+func Benchmark(trainers map[string]Trainer, lfs LabeledFeatureSet, folds int) map[string]BenchResult {
+	names := make([]string, 0, len(trainers))
+	for name := range trainers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make(map[string]BenchResult)
+	for _, name := range names {
+		tt := &timedTrainer{Trainer: trainers[name]}
+		acc, _, err := CrossValidate(tt, lfs, folds)
+		res := BenchResult{Accuracy: acc, Err: err}
+		if err == nil {
+			res.TrainTime = tt.trainTime / time.Duration(folds)
+			if tt.predictions > 0 {
+				res.PredictTime = tt.predictTime / time.Duration(tt.predictions)
+			}
+		}
+		results[name] = res
+	}
+	return results
+}
@@ -0,0 +1,218 @@
+package pr
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+/*
+	binaryScore returns the score of the positive label minus the score of the
+	other label for a feature x. scores is a buffer of length 2.
+*/
+func binaryScore(sc ScoredClassifier, x, scores []float64, positiveLabel int) float64 {
+	sc.Scores(x, scores)
+	return scores[positiveLabel] - scores[1-positiveLabel]
+}
+
+type scoredSample struct {
+	score    float64
+	positive bool
+}
+
+/*
+	PRCurve computes the precision-recall curve of a binary ScoredClassifier on
+	a LabeledFeatureSet with exactly two labels. The threshold is swept from the
+	highest to the lowest binary score (the score of positiveLabel minus the
+	score of the other label), so the returned points are ordered by
+	non-decreasing recall. Samples with equal scores are grouped into one point.
+
+	nil, nil is returned if lfs does not have two labels or has no positive
+	samples.
+*/
+func PRCurve(sc ScoredClassifier, lfs LabeledFeatureSet, positiveLabel int) (precision, recall []float64) {
+	if lfs.LabelCount() != 2 || positiveLabel < 0 || positiveLabel > 1 {
+		return nil, nil
+	}
+
+	samples := collectBinaryScores(sc, lfs, positiveLabel)
+	posCnt := lfs.FeatureCount(positiveLabel)
+	if posCnt == 0 {
+		return nil, nil
+	}
+
+	tp, fp := 0, 0
+	for i, s := range samples {
+		if s.positive {
+			tp++
+		} else {
+			fp++
+		}
+		if i+1 < len(samples) && samples[i+1].score == s.score {
+			continue
+		}
+		precision = append(precision, float64(tp)/float64(tp+fp))
+		recall = append(recall, float64(tp)/float64(posCnt))
+	}
+
+	return precision, recall
+}
+
+/*
+	collectBinaryScores returns the binary scores of all samples in lfs, sorted
+	in descending order.
+*/
+func collectBinaryScores(sc ScoredClassifier, lfs LabeledFeatureSet, positiveLabel int) []scoredSample {
+	x := make([]float64, lfs.Dim())
+	scores := make([]float64, 2)
+
+	var samples []scoredSample
+	for lbl := 0; lbl < 2; lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			lfs.FetchFeature(lbl, i, x)
+			samples = append(samples, scoredSample{
+				score:    binaryScore(sc, x, scores, positiveLabel),
+				positive: lbl == positiveLabel,
+			})
+		}
+	}
+
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].score > samples[j].score
+	})
+
+	return samples
+}
+
+/*
+	AveragePrecision summarizes a precision-recall curve, as returned by PRCurve,
+	as the weighted mean of precisions, where the weight of each point is the
+	increase in recall from the previous point:
+
+	  AP = sum_n (R_n - R_(n-1)) * P_n
+
+	0 is returned if precision and recall have different lengths.
+*/
+func AveragePrecision(precision, recall []float64) float64 {
+	if len(precision) != len(recall) {
+		return 0
+	}
+	ap, prevRecall := 0., 0.
+	for i := range precision {
+		ap += (recall[i] - prevRecall) * precision[i]
+		prevRecall = recall[i]
+	}
+	return ap
+}
+
+/*
+	A ThresholdClassifier turns a binary ScoredClassifier into a Classifier with
+	an operating threshold on the binary score (the score of PositiveLabel minus
+	the score of the other label) instead of taking the label with the largest
+	score.
+*/
+type ThresholdClassifier struct {
+	// the underlying classifier, whose labels must be 0 and 1
+	Scorer ScoredClassifier
+	// features with a binary score larger than Threshold are positive
+	Threshold float64
+	// the positive label, 0 or 1
+	PositiveLabel int
+}
+
+// binaryScoresPool holds buffers of two scores for ThresholdClassifier.Classify.
+var binaryScoresPool = sync.Pool{
+	New: func() interface{} { return new([2]float64) },
+}
+
+// Implementation of Classifier.Classify. It is safe for concurrent use if
+// Scorer is.
+func (tc *ThresholdClassifier) Classify(x []float64) int {
+	scores := binaryScoresPool.Get().(*[2]float64)
+	score := binaryScore(tc.Scorer, x, scores[:], tc.PositiveLabel)
+	binaryScoresPool.Put(scores)
+
+	if score > tc.Threshold {
+		return tc.PositiveLabel
+	}
+	return 1 - tc.PositiveLabel
+}
+
+// Implementation of ScoredClassifier.Scores
+func (tc *ThresholdClassifier) Scores(x []float64, scores []float64) {
+	tc.Scorer.Scores(x, scores)
+}
+
+/*
+	BestThreshold scans all distinct thresholds of the binary scores of sc on
+	lfs and returns the one maximizing metric, which is called with the
+	ConfusionMatrix a ThresholdClassifier with that threshold would give, together
+	with the maximal value of metric.
+
+	If sc is a *ThresholdClassifier, the threshold is tuned for its Scorer and
+	PositiveLabel, i.e. it can be assigned to its Threshold. Otherwise label 1 is
+	the positive label.
+
+	lfs must have exactly two labels, otherwise 0 and -Inf are returned.
+*/
+func BestThreshold(sc ScoredClassifier, lfs LabeledFeatureSet, metric func(ConfusionMatrix) float64) (threshold, best float64) {
+	positiveLabel := 1
+	if tc, ok := sc.(*ThresholdClassifier); ok {
+		sc, positiveLabel = tc.Scorer, tc.PositiveLabel
+	}
+	if lfs.LabelCount() != 2 || positiveLabel < 0 || positiveLabel > 1 {
+		return 0, math.Inf(-1)
+	}
+	negativeLabel := 1 - positiveLabel
+
+	samples := collectBinaryScores(sc, lfs, positiveLabel)
+	posCnt := lfs.FeatureCount(positiveLabel)
+	negCnt := lfs.FeatureCount(negativeLabel)
+
+	cm := NewConfusionMatrix(2)
+	tp, fp := 0, 0
+	best = math.Inf(-1)
+	// the first i samples are classified as positive
+	for i := 0; i <= len(samples); i++ {
+		if i > 0 {
+			if samples[i-1].positive {
+				tp++
+			} else {
+				fp++
+			}
+			if i < len(samples) && samples[i].score == samples[i-1].score {
+				continue
+			}
+		}
+
+		cm.Counts[positiveLabel][positiveLabel] = tp
+		cm.Counts[positiveLabel][negativeLabel] = posCnt - tp
+		cm.Counts[negativeLabel][positiveLabel] = fp
+		cm.Counts[negativeLabel][negativeLabel] = negCnt - fp
+
+		if v := metric(cm); v > best {
+			best = v
+			switch {
+			case len(samples) == 0:
+				threshold = 0
+			case i == 0:
+				threshold = samples[0].score
+			case i == len(samples):
+				threshold = math.Inf(-1)
+			default:
+				/*
+					any threshold in [samples[i].score, samples[i-1].score)
+					separates them; the midpoint rounds to samples[i-1].score
+					for adjacent floats, then the lower score is taken
+				*/
+				threshold = (samples[i-1].score + samples[i].score) / 2
+				if threshold >= samples[i-1].score {
+					threshold = samples[i].score
+				}
+			}
+		}
+	}
+
+	return threshold, best
+}
@@ -0,0 +1,89 @@
+package pr
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// linearScorer scores label 1 by x[0] and label 0 by zero.
+type linearScorer struct{}
+
+// Implementation of ScoredClassifier.Scores
+func (linearScorer) Scores(x []float64, scores []float64) {
+	scores[0], scores[1] = 0, x[0]
+}
+
+// Implementation of Classifier.Classify
+func (linearScorer) Classify(x []float64) int {
+	if x[0] > 0 {
+		return 1
+	}
+	return 0
+}
+
+func TestPRCurveAveragePrecision(t *testing.T) {
+	/*
+		scores of positives: 5, 3, 3, 1; of negatives: 4, 3, 0
+
+		score  5  4  3(+,+,-)  1  0
+		tp     1  1  3         4  4
+		fp     0  1  2         2  3
+	*/
+	sfs := &SliceFeatureSet{
+		FeatureDim: 1,
+		Features:   [][][]float64{{{4}, {3}, {0}}, {{5}, {3}, {3}, {1}}},
+	}
+	precision, recall := PRCurve(linearScorer{}, sfs, 1)
+	wantP := []float64{1, 0.5, 3. / 5, 4. / 6, 4. / 7}
+	wantR := []float64{0.25, 0.25, 0.75, 1, 1}
+	if !reflect.DeepEqual(precision, wantP) || !reflect.DeepEqual(recall, wantR) {
+		t.Fatalf("PRCurve gives precision %v, recall %v, expected %v, %v", precision, recall, wantP, wantR)
+	}
+
+	/* 0.25*1 + 0 + 0.5*3/5 + 0.25*4/6 + 0 */
+	want := 0.25 + 0.3 + 1./6
+	if ap := AveragePrecision(precision, recall); math.Abs(ap-want) > 1e-12 {
+		t.Errorf("AveragePrecision gives %v, expected %v", ap, want)
+	}
+	if ap := AveragePrecision(precision, recall[:3]); ap != 0 {
+		t.Errorf("AveragePrecision of mismatched lengths gives %v, expected 0", ap)
+	}
+}
+
+func TestBestThresholdAdjacentScores(t *testing.T) {
+	/* the positive and the negative scores are adjacent floats */
+	hi := 1e10
+	lo := math.Nextafter(hi, 0)
+	sfs := &SliceFeatureSet{
+		FeatureDim: 1,
+		Features:   [][][]float64{{{lo}, {-1}}, {{hi}, {2e10}}},
+	}
+	accuracy := func(cm ConfusionMatrix) float64 { return cm.Accuracy() }
+
+	for _, positive := range []int{1, 0} {
+		tc := &ThresholdClassifier{Scorer: linearScorer{}, PositiveLabel: positive}
+		threshold, best := BestThreshold(tc, sfs, accuracy)
+		if best != 1 {
+			t.Errorf("positive label %d: best accuracy is %v, expected 1", positive, best)
+		}
+		tc.Threshold = threshold
+		if acc := Accuracy(tc, sfs); acc != best {
+			t.Errorf("positive label %d: threshold %v gives accuracy %v, expected the best %v", positive, threshold, acc, best)
+		}
+	}
+
+	/* a plain ScoredClassifier takes label 1 as positive */
+	threshold, _ := BestThreshold(linearScorer{}, sfs, accuracy)
+	if acc := Accuracy(&ThresholdClassifier{Scorer: linearScorer{}, Threshold: threshold, PositiveLabel: 1}, sfs); acc != 1 {
+		t.Errorf("threshold %v gives accuracy %v, expected 1", threshold, acc)
+	}
+}
+
+func TestThresholdClassifierNoAllocs(t *testing.T) {
+	tc := &ThresholdClassifier{Scorer: linearScorer{}, Threshold: 0.5, PositiveLabel: 1}
+	x := []float64{1}
+	if allocs := testing.AllocsPerRun(100, func() { tc.Classify(x) }); allocs != 0 {
+		t.Errorf("Classify allocates %v times per call, expected none", allocs)
+	}
+}
@@ -0,0 +1,96 @@
+package pr
+
+import (
+	"math"
+)
+
+/*
+	ReliabilityDiagram bins the features of lfs by the top probability given by
+	pc into bins equal-width bins over [0, 1], and returns the mean top
+	probability, the fraction of correct predictions (the top-probability label
+	being the actual label) and the number of features of every bin. Empty bins
+	have zero confidence and accuracy.
+*/
+func ReliabilityDiagram(pc ProbClassifier, lfs LabeledFeatureSet, bins int) (confidence, accuracy []float64, counts []int) {
+	confidence = make([]float64, bins)
+	accuracy = make([]float64, bins)
+	counts = make([]int, bins)
+
+	x := make([]float64, lfs.Dim())
+	for lbl := 0; lbl < lfs.LabelCount(); lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			lfs.FetchFeature(lbl, i, x)
+			probs := pc.ClassifyProb(x)
+			pred := argmax(probs)
+
+			b := int(probs[pred] * float64(bins))
+			if b >= bins {
+				b = bins - 1
+			}
+			confidence[b] += probs[pred]
+			if pred == lbl {
+				accuracy[b]++
+			}
+			counts[b]++
+		}
+	}
+
+	for b, cnt := range counts {
+		if cnt > 0 {
+			confidence[b] /= float64(cnt)
+			accuracy[b] /= float64(cnt)
+		}
+	}
+
+	return confidence, accuracy, counts
+}
+
+/*
+	ExpectedCalibrationError returns the mean absolute difference between the
+	confidence and the accuracy of the bins of a reliability diagram, weighted
+	by the number of features in every bin. 0 is returned if all bins are
+	empty.
+*/
+func ExpectedCalibrationError(confidence, accuracy []float64, counts []int) float64 {
+	ece, total := 0., 0
+	for b, cnt := range counts {
+		ece += float64(cnt) * math.Abs(confidence[b]-accuracy[b])
+		total += cnt
+	}
+	if total == 0 {
+		return 0
+	}
+	return ece / float64(total)
+}
+
+/*
+	AccuracyCoverage evaluates selective prediction: only the features whose
+	top probability given by pc exceeds threshold are covered. It returns the
+	accuracy on the covered features and the fraction of covered features, or
+	0, 0 if nothing is covered.
+*/
+func AccuracyCoverage(pc ProbClassifier, lfs LabeledFeatureSet, threshold float64) (accuracy, coverage float64) {
+	x := make([]float64, lfs.Dim())
+	covered, correct, total := 0, 0, 0
+	for lbl := 0; lbl < lfs.LabelCount(); lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			lfs.FetchFeature(lbl, i, x)
+			probs := pc.ClassifyProb(x)
+			pred := argmax(probs)
+			if probs[pred] > threshold {
+				covered++
+				if pred == lbl {
+					correct++
+				}
+			}
+		}
+		total += cnt
+	}
+
+	if covered == 0 {
+		return 0, 0
+	}
+	return float64(correct) / float64(covered), float64(covered) / float64(total)
+}
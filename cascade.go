@@ -0,0 +1,41 @@
+package pr
+
+/*
+	CascadeStage is a stage of a CascadeClassifier.
+*/
+type CascadeStage struct {
+	Classifier ProbClassifier
+	// the prediction of the stage is accepted if its top probability exceeds
+	// Threshold
+	Threshold float64
+}
+
+/*
+	A CascadeClassifier tries its stages in order, e.g. a fast model followed by a
+	slow but accurate one, and answers with the first stage that is confident
+	enough. The last stage always answers.
+*/
+type CascadeClassifier struct {
+	Stages []CascadeStage
+}
+
+/*
+	ClassifyStage classifies x and returns the label together with the index of
+	the stage that answered.
+*/
+func (cc *CascadeClassifier) ClassifyStage(x []float64) (label, stage int) {
+	for i, st := range cc.Stages {
+		probs := st.Classifier.ClassifyProb(x)
+		label = argmax(probs)
+		if i == len(cc.Stages)-1 || probs[label] > st.Threshold {
+			return label, i
+		}
+	}
+	return -1, -1
+}
+
+// Implementation of Classifier.Classify
+func (cc *CascadeClassifier) Classify(x []float64) int {
+	label, _ := cc.ClassifyStage(x)
+	return label
+}
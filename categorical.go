@@ -0,0 +1,112 @@
+package pr
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+	A *CategoricalNBClassifier is a naive Bayes classifier for features whose
+	dimensions are unordered categories encoded as integers 0..cardinality-1.
+*/
+type CategoricalNBClassifier struct {
+	// LogProbs[label][k][c] is the logarithm of the probability of category c
+	// in dimension k given label
+	LogProbs [][][]float64
+	// the logarithm of prior priorities
+	LogPrior []float64
+}
+
+// Implementation of Classifier.Classify. A dimension whose value is not a
+// valid category is ignored.
+func (nb *CategoricalNBClassifier) Classify(x []float64) int {
+	bestLogP := 0.
+	bestLabel := -1
+
+	for lbl := range nb.LogPrior {
+		logP := nb.LogPrior[lbl]
+		for k, probs := range nb.LogProbs[lbl] {
+			if c, ok := category(x[k], len(probs)); ok {
+				logP += probs[c]
+			}
+		}
+
+		if bestLabel < 0 || logP > bestLogP {
+			bestLabel, bestLogP = lbl, logP
+		}
+	}
+
+	return bestLabel
+}
+
+/*
+	category converts v into a category index. ok is false if v is not an
+	integer in 0..cardinality-1.
+*/
+func category(v float64, cardinality int) (c int, ok bool) {
+	if v != math.Trunc(v) || v < 0 || v >= float64(cardinality) {
+		return 0, false
+	}
+	return int(v), true
+}
+
+/*
+	CategoricalNBTrain trains a *CategoricalNBClassifier from a
+	LabeledFeatureSet. cardinalities[k] is the number of categories of
+	dimension k. Probabilities are estimated with Laplace smoothing, i.e. every
+	category gets one extra count, for both the categories and the priors.
+
+	An error is returned if a feature value is not an integer within the
+	cardinality of its dimension.
+*/
+func CategoricalNBTrain(lfs LabeledFeatureSet, cardinalities []int) (*CategoricalNBClassifier, error) {
+	lblCnt := lfs.LabelCount()
+	dim := lfs.Dim()
+	if len(cardinalities) != dim {
+		return nil, fmt.Errorf("%d cardinalities for dimension %d", len(cardinalities), dim)
+	}
+
+	clsfr := &CategoricalNBClassifier{
+		LogProbs: make([][][]float64, lblCnt),
+		LogPrior: make([]float64, lblCnt),
+	}
+
+	x := make([]float64, dim)
+
+	total := 0
+	for lbl := range clsfr.LogProbs {
+		counts := make([][]float64, dim)
+		for k := range counts {
+			counts[k] = make([]float64, cardinalities[k])
+		}
+
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			lfs.FetchFeature(lbl, i, x)
+			for k := range x {
+				c, ok := category(x[k], cardinalities[k])
+				if !ok {
+					return nil, fmt.Errorf("label %d, feature %d: value %v of dimension %d is not a category in 0..%d",
+						lbl, i, x[k], k, cardinalities[k]-1)
+				}
+				counts[k][c]++
+			}
+		}
+
+		for k, cs := range counts {
+			for c := range cs {
+				cs[c] = math.Log((cs[c] + 1) / float64(cnt+cardinalities[k]))
+			}
+		}
+
+		clsfr.LogProbs[lbl] = counts
+		clsfr.LogPrior[lbl] = float64(cnt)
+		total += cnt
+	}
+
+	for lbl := range clsfr.LogPrior {
+		clsfr.LogPrior[lbl] = math.Log((clsfr.LogPrior[lbl] + 1) / float64(total+lblCnt))
+	}
+
+	return clsfr, nil
+}
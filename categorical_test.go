@@ -0,0 +1,63 @@
+package pr
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCategoricalNBTrain(t *testing.T) {
+	/* dimension 0 has 2 categories, dimension 1 has 3 */
+	sfs := &SliceFeatureSet{
+		FeatureDim: 2,
+		Features: [][][]float64{
+			{{0, 0}, {0, 1}, {1, 0}},
+			{{1, 2}},
+		},
+	}
+	nb, err := CategoricalNBTrain(sfs, []int{2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	/*
+		label 0, 3 features: dimension 0 counts (2, 1) -> (3/5, 2/5),
+		dimension 1 counts (2, 1, 0) -> (3/6, 2/6, 1/6)
+		label 1, 1 feature: dimension 0 (0, 1) -> (1/3, 2/3), dimension 1
+		(0, 0, 1) -> (1/4, 1/4, 2/4)
+		priors (3+1)/(4+2), (1+1)/(4+2)
+	*/
+	want := [][][]float64{
+		{{3. / 5, 2. / 5}, {3. / 6, 2. / 6, 1. / 6}},
+		{{1. / 3, 2. / 3}, {1. / 4, 1. / 4, 2. / 4}},
+	}
+	for lbl := range want {
+		for k := range want[lbl] {
+			for c, p := range want[lbl][k] {
+				if got := math.Exp(nb.LogProbs[lbl][k][c]); math.Abs(got-p) > 1e-12 {
+					t.Errorf("label %d, dimension %d, category %d: probability %v, expected %v", lbl, k, c, got, p)
+				}
+			}
+		}
+	}
+	for lbl, p := range []float64{4. / 6, 2. / 6} {
+		if got := math.Exp(nb.LogPrior[lbl]); math.Abs(got-p) > 1e-12 {
+			t.Errorf("label %d: prior %v, expected %v", lbl, got, p)
+		}
+	}
+
+	if lbl := nb.Classify([]float64{0, 0}); lbl != 0 {
+		t.Errorf("(0, 0) is classified as %d, expected 0", lbl)
+	}
+	/* label 0: 4/6 * 2/5 * 1/6 = 2/45, label 1: 2/6 * 2/3 * 2/4 = 1/9 */
+	if lbl := nb.Classify([]float64{1, 2}); lbl != 1 {
+		t.Errorf("(1, 2) is classified as %d, expected 1", lbl)
+	}
+
+	sfs.Features[1] = append(sfs.Features[1], []float64{0.5, 0})
+	if _, err := CategoricalNBTrain(sfs, []int{2, 3}); err == nil {
+		t.Error("expected an error for a non-integer category")
+	}
+	if _, err := CategoricalNBTrain(sfs, []int{2}); err == nil {
+		t.Error("expected an error for mismatched cardinalities")
+	}
+}
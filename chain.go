@@ -0,0 +1,229 @@
+package pr
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+	A ChainClassifier classifies a feature in stages, e.g. a coarse label and
+	then a fine label. The input of stage s is the feature followed by the
+	one-hot vectors of the labels predicted by stages 0..s-1, so its dimension
+	is the feature dimension plus the sum of LabelCounts[0..s-1].
+*/
+type ChainClassifier struct {
+	// the classifiers of all stages
+	Stages []Classifier
+	// the number of labels of every stage
+	LabelCounts []int
+}
+
+/*
+	NewChainClassifier returns a *ChainClassifier with the classifiers of the
+	stages and their label counts. An error is returned if there are no stages
+	or the numbers of stages and label counts differ.
+*/
+func NewChainClassifier(stages []Classifier, labelCounts []int) (*ChainClassifier, error) {
+	if len(stages) == 0 {
+		return nil, errors.New("no stages")
+	}
+	if len(labelCounts) != len(stages) {
+		return nil, fmt.Errorf("%d label counts for %d stages", len(labelCounts), len(stages))
+	}
+	return &ChainClassifier{Stages: stages, LabelCounts: labelCounts}, nil
+}
+
+/*
+	ClassifyAll returns the labels predicted by all stages. If a stage rejects
+	the feature, i.e. returns RejectLabel or any label out of its range, it and
+	all later stages get RejectLabel.
+*/
+func (cc *ChainClassifier) ClassifyAll(x []float64) []int {
+	labels := make([]int, len(cc.Stages))
+	input := append([]float64(nil), x...)
+	for s, c := range cc.Stages {
+		labels[s] = c.Classify(input)
+		oh := OneHot(labels[s], cc.LabelCounts[s])
+		if oh == nil {
+			for ; s < len(labels); s++ {
+				labels[s] = RejectLabel
+			}
+			break
+		}
+		input = append(input, oh...)
+	}
+	return labels
+}
+
+// Implementation of Classifier.Classify. The label of the last stage is
+// returned, RejectLabel if a stage rejects or there are no stages.
+func (cc *ChainClassifier) Classify(x []float64) int {
+	labels := cc.ClassifyAll(x)
+	if len(labels) == 0 {
+		return RejectLabel
+	}
+	return labels[len(labels)-1]
+}
+
+// the ridge added to the covariances of the stages of a chain trained by a
+// GaussianTrainer, making the constant one-hot dimensions invertible
+const chainEpsilon = 1e-6
+
+/*
+	A ChainTrainer trains a ChainClassifier. The labels of the training
+	LabeledFeatureSet are those of the last stage; the label of an earlier stage
+	s is derived by StageLabels[s][label].
+
+	While training stage s, the one-hot vectors of the actual (not predicted)
+	labels of stages 0..s-1 are appended to the features. With hierarchical
+	labels, these dimensions are constant within a label of stage s, so the
+	Trainers must tolerate constant dimensions. A *GaussianTrainer without a
+	DiagonalEpsilon therefore trains the stages after the first with a small
+	DiagonalEpsilon (1e-6); other Gaussian-based trainers, e.g. LDATrainer, do
+	not tolerate them.
+*/
+type ChainTrainer struct {
+	// the trainers of all stages, the last one trains the final labels
+	Trainers []Trainer
+	// StageLabels[s][label] is the label of stage s for a final label, for all
+	// but the last stage
+	StageLabels [][]int
+}
+
+/*
+	TrainChain trains a *ChainClassifier on lfs.
+*/
+func (ct *ChainTrainer) TrainChain(lfs LabeledFeatureSet) (*ChainClassifier, error) {
+	stageCnt := len(ct.Trainers)
+	if stageCnt == 0 {
+		return nil, errors.New("no stages")
+	}
+	if len(ct.StageLabels) != stageCnt-1 {
+		return nil, fmt.Errorf("%d stage label mappings for %d stages", len(ct.StageLabels), stageCnt)
+	}
+
+	lblCnt := lfs.LabelCount()
+	mappings := make([][]int, stageCnt)
+	labelCounts := make([]int, stageCnt)
+	for s := range mappings {
+		if s == stageCnt-1 {
+			mappings[s] = make([]int, lblCnt)
+			for lbl := range mappings[s] {
+				mappings[s][lbl] = lbl
+			}
+		} else {
+			mappings[s] = ct.StageLabels[s]
+		}
+		if len(mappings[s]) != lblCnt {
+			return nil, fmt.Errorf("stage %d: %d label mappings for %d labels", s, len(mappings[s]), lblCnt)
+		}
+		for _, sl := range mappings[s] {
+			if sl < 0 {
+				return nil, fmt.Errorf("stage %d: negative label %d", s, sl)
+			}
+			if sl >= labelCounts[s] {
+				labelCounts[s] = sl + 1
+			}
+		}
+	}
+
+	stages := make([]Classifier, stageCnt)
+	for s, t := range ct.Trainers {
+		if gt, ok := t.(*GaussianTrainer); ok && s > 0 && gt.DiagonalEpsilon == 0 {
+			ridged := *gt
+			ridged.DiagonalEpsilon = chainEpsilon
+			t = &ridged
+		}
+		c := t.Train(newChainStageSet(lfs, mappings[:s+1], labelCounts[:s]))
+		if c == nil {
+			return nil, fmt.Errorf("stage %d: training failed", s)
+		}
+		stages[s] = c
+	}
+
+	return NewChainClassifier(stages, labelCounts)
+}
+
+// Implementation of Trainer.Train. nil is returned if training fails.
+func (ct *ChainTrainer) Train(lfs LabeledFeatureSet) Classifier {
+	cc, err := ct.TrainChain(lfs)
+	if err != nil {
+		return nil
+	}
+	return cc
+}
+
+/*
+	chainStageSet is the training view of a stage: features are relabeled to
+	the labels of the stage, with the one-hot vectors of the labels of previous
+	stages appended.
+*/
+type chainStageSet struct {
+	lfs LabeledFeatureSet
+	// mappings of the previous stages and, last, of this stage
+	mappings    [][]int
+	labelCounts []int
+	dim         int
+	// items[label] are the original (label, index) pairs of the stage label
+	items [][][2]int
+}
+
+func newChainStageSet(lfs LabeledFeatureSet, mappings [][]int, labelCounts []int) *chainStageSet {
+	css := &chainStageSet{
+		lfs:         lfs,
+		mappings:    mappings,
+		labelCounts: labelCounts,
+		dim:         lfs.Dim(),
+	}
+	for _, cnt := range labelCounts {
+		css.dim += cnt
+	}
+
+	mapping := mappings[len(mappings)-1]
+	stageLblCnt := 0
+	for _, sl := range mapping {
+		if sl >= stageLblCnt {
+			stageLblCnt = sl + 1
+		}
+	}
+	css.items = make([][][2]int, stageLblCnt)
+	for lbl, sl := range mapping {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			css.items[sl] = append(css.items[sl], [2]int{lbl, i})
+		}
+	}
+
+	return css
+}
+
+// Implementation of LabeledFeatureSet.Dim
+func (css *chainStageSet) Dim() int {
+	return css.dim
+}
+
+// Implementation of LabeledFeatureSet.LabelCount
+func (css *chainStageSet) LabelCount() int {
+	return len(css.items)
+}
+
+// Implementation of LabeledFeatureSet.FeatureCount
+func (css *chainStageSet) FeatureCount(label int) int {
+	return len(css.items[label])
+}
+
+// Implementation of LabeledFeatureSet.FetchFeature
+func (css *chainStageSet) FetchFeature(label, index int, x []float64) {
+	item := css.items[label][index]
+	featDim := css.lfs.Dim()
+	css.lfs.FetchFeature(item[0], item[1], x[:featDim])
+
+	off := featDim
+	for s, cnt := range css.labelCounts {
+		for l := 0; l < cnt; l++ {
+			x[off+l] = 0
+		}
+		x[off+css.mappings[s][item[0]]] = 1
+		off += cnt
+	}
+}
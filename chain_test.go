@@ -0,0 +1,93 @@
+package pr
+
+import (
+	"reflect"
+	"testing"
+)
+
+// constClassifier classifies every feature of dimension dim as label.
+type constClassifier struct {
+	dim   int
+	label int
+}
+
+// Implementation of Classifier.Classify
+func (cc constClassifier) Classify(x []float64) int {
+	if len(x) != cc.dim {
+		panic("unexpected feature dimension")
+	}
+	return cc.label
+}
+
+func TestNewChainClassifierEmpty(t *testing.T) {
+	if _, err := NewChainClassifier(nil, nil); err == nil {
+		t.Error("expected an error for no stages")
+	}
+	if _, err := NewChainClassifier([]Classifier{constClassifier{2, 0}}, []int{2, 3}); err == nil {
+		t.Error("expected an error for mismatched label counts")
+	}
+	if _, err := (&ChainTrainer{}).TrainChain(randomSet(1, 5, []float64{0}, []float64{1})); err == nil {
+		t.Error("expected an error training no stages")
+	}
+	if lbl := (&ChainClassifier{}).Classify([]float64{0}); lbl != RejectLabel {
+		t.Errorf("an empty chain classifies as %d, expected RejectLabel", lbl)
+	}
+}
+
+func TestChainClassifierReject(t *testing.T) {
+	for _, c := range []struct {
+		first int
+		want  []int
+	}{
+		{1, []int{1, 4, 0}},
+		{RejectLabel, []int{RejectLabel, RejectLabel, RejectLabel}},
+		/* out of the range of the first stage */
+		{2, []int{RejectLabel, RejectLabel, RejectLabel}},
+	} {
+		cc, err := NewChainClassifier([]Classifier{
+			constClassifier{3, c.first},
+			constClassifier{5, 4},
+			constClassifier{10, 0},
+		}, []int{2, 5, 3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		x := []float64{0, 1, 2}
+		if got := cc.ClassifyAll(x); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("first stage %d: ClassifyAll gives %v, expected %v", c.first, got, c.want)
+		}
+		if got, want := cc.Classify(x), c.want[2]; got != want {
+			t.Errorf("first stage %d: Classify gives %d, expected %d", c.first, got, want)
+		}
+	}
+}
+
+func TestChainTrainerGaussian(t *testing.T) {
+	means := [][]float64{{0, 0}, {0, 6}, {9, 0}, {9, 6}}
+	coarse := []int{0, 0, 1, 1}
+	ct := &ChainTrainer{
+		Trainers:    []Trainer{&GaussianTrainer{}, &GaussianTrainer{}},
+		StageLabels: [][]int{coarse},
+	}
+	cc, err := ct.TrainChain(randomSet(1, 100, means...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eps := ct.Trainers[1].(*GaussianTrainer).DiagonalEpsilon; eps != 0 {
+		t.Errorf("training modified the DiagonalEpsilon of the trainer to %v", eps)
+	}
+
+	test := randomSet(2, 100, means...)
+	if acc := Accuracy(cc, test); acc < 0.9 {
+		t.Errorf("accuracy is %v, expected at least 0.9", acc)
+	}
+	x := make([]float64, 2)
+	for lbl := range test.Features {
+		for i := range test.Features[lbl] {
+			test.FetchFeature(lbl, i, x)
+			if labels := cc.ClassifyAll(x); labels[0] != coarse[labels[1]] {
+				t.Fatalf("x = %v: fine label %d is inconsistent with coarse label %d", x, labels[1], labels[0])
+			}
+		}
+	}
+}
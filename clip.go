@@ -0,0 +1,87 @@
+package pr
+
+import (
+	"sync/atomic"
+)
+
+/*
+	A RangeClipper clips every dimension of features into the range observed
+	in training, so that a single wild value cannot dominate the quadratic form
+	of a Gaussian model.
+*/
+type RangeClipper struct {
+	// the range is extended on both sides by Margin times its width
+	Margin float64
+	// the observed minimum and maximum of every dimension
+	Min, Max []float64
+
+	clipped int64
+}
+
+/*
+	Fit records the minimum and the maximum of every dimension of the features
+	in lfs.
+*/
+func (rc *RangeClipper) Fit(lfs LabeledFeatureSet) {
+	dim := lfs.Dim()
+	rc.Min = make([]float64, dim)
+	rc.Max = make([]float64, dim)
+
+	x := make([]float64, dim)
+	first := true
+	for lbl := 0; lbl < lfs.LabelCount(); lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			lfs.FetchFeature(lbl, i, x)
+			for k, v := range x {
+				if first || v < rc.Min[k] {
+					rc.Min[k] = v
+				}
+				if first || v > rc.Max[k] {
+					rc.Max[k] = v
+				}
+			}
+			first = false
+		}
+	}
+}
+
+/*
+	Transform returns x with every dimension clipped into its range.
+*/
+func (rc *RangeClipper) Transform(x []float64) []float64 {
+	y := make([]float64, len(x))
+	clipped := int64(0)
+	for k, v := range x {
+		margin := rc.Margin * (rc.Max[k] - rc.Min[k])
+		switch {
+		case v < rc.Min[k]-margin:
+			v = rc.Min[k] - margin
+			clipped++
+		case v > rc.Max[k]+margin:
+			v = rc.Max[k] + margin
+			clipped++
+		}
+		y[k] = v
+	}
+	if clipped > 0 {
+		atomic.AddInt64(&rc.clipped, clipped)
+	}
+	return y
+}
+
+/*
+	TransformSet returns a view of lfs with every feature transformed by
+	Transform.
+*/
+func (rc *RangeClipper) TransformSet(lfs LabeledFeatureSet) LabeledFeatureSet {
+	return newTransformedSet(lfs, len(rc.Min), rc.Transform)
+}
+
+/*
+	ClippedCount returns the number of values clipped by Transform so far, which
+	can be monitored for drift of the input distribution.
+*/
+func (rc *RangeClipper) ClippedCount() int {
+	return int(atomic.LoadInt64(&rc.clipped))
+}
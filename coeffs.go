@@ -0,0 +1,65 @@
+package pr
+
+/*
+	GaussianCoeffs is a self-contained table of the coefficients of a
+	GaussianClassifier in plain slices, which can be evaluated without this
+	package or go.matrix. The score of a feature x on a label is
+
+	  LogCoefs[l] + sum_{i,j} (x[i]-Means[l][i])*Precs[l][i*Dim+j]*(x[j]-Means[l][j]) + LogPrior[l],
+
+	and the classified label is the one with the largest score.
+*/
+type GaussianCoeffs struct {
+	// the dimension of features
+	Dim int
+	// the number of labels
+	LabelCount int
+	// the means, LabelCount x Dim
+	Means [][]float64
+	// the inverse matrices of Sigma times -1/2, row-major, LabelCount x (Dim*Dim)
+	Precs [][]float64
+	// logarithm coefficents, log(1/(sqrt((2*Pi)^k*det(Sigma)))
+	LogCoefs []float64
+	// the logarithm of prior probabilities, all zeros for uniform priors
+	LogPrior []float64
+}
+
+/*
+	ExportCoefficients returns the coefficient table of gc. Feature weights, if
+	set, are folded into the precision matrices. The table shares no slices with
+	gc.
+*/
+func (gc *GaussianClassifier) ExportCoefficients() GaussianCoeffs {
+	lblCnt, dim := gc.LabelCount(), gc.Dim()
+	coeffs := GaussianCoeffs{
+		Dim:        dim,
+		LabelCount: lblCnt,
+		Means:      cloneSlices(gc.Means),
+		Precs:      cloneSlices(gc.Precs),
+		LogCoefs:   append([]float64(nil), gc.LogCoefs...),
+		LogPrior:   make([]float64, lblCnt),
+	}
+	if gc.LogPrior != nil {
+		copy(coeffs.LogPrior, gc.LogPrior)
+	}
+	for lbl, prec := range coeffs.Precs {
+		coeffs.Precs[lbl] = gc.weightedPrec(prec)
+	}
+	return coeffs
+}
+
+/*
+	ImportCoefficients returns a *GaussianClassifier with the coefficients of
+	coeffs. The classifier shares no slices with coeffs.
+*/
+func ImportCoefficients(coeffs GaussianCoeffs) *GaussianClassifier {
+	gc := &GaussianClassifier{
+		Means:    cloneSlices(coeffs.Means),
+		Precs:    cloneSlices(coeffs.Precs),
+		LogCoefs: append([]float64(nil), coeffs.LogCoefs...),
+	}
+	if coeffs.LogPrior != nil {
+		gc.LogPrior = append([]float64(nil), coeffs.LogPrior...)
+	}
+	return gc
+}
@@ -0,0 +1,85 @@
+package pr
+
+import (
+	"math"
+)
+
+/*
+	NumParameters returns the number of free parameters of the model: the means
+	(labels*dim), the covariances (labels*dim*(dim+1)/2 for full models,
+	labels*dim for diagonal ones, and only one covariance if all labels share
+	it, as in LDA), and labels-1 priors if a prior is set.
+*/
+func (gc *GaussianClassifier) NumParameters() int {
+	lblCnt, dim := gc.LabelCount(), gc.Dim()
+
+	covCnt := lblCnt
+	if lblCnt > 1 && gc.sharedPrec() != nil {
+		covCnt = 1
+	}
+	perCov := dim * (dim + 1) / 2
+	if gc.Diagonal {
+		perCov = dim
+	}
+
+	n := lblCnt*dim + covCnt*perCov
+	if gc.LogPrior != nil && lblCnt > 0 {
+		n += lblCnt - 1
+	}
+	return n
+}
+
+/*
+	totalLogLikelyhood returns the sum of LogPosterior(label, x) over all
+	features x of all labels in lfs, and the number of features.
+*/
+func (gc *GaussianClassifier) totalLogLikelyhood(lfs LabeledFeatureSet) (float64, int) {
+	x := make([]float64, lfs.Dim())
+	sum, total := 0., 0
+	for lbl := 0; lbl < lfs.LabelCount(); lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			lfs.FetchFeature(lbl, i, x)
+			sum += gc.LogPosterior(lbl, x)
+		}
+		total += cnt
+	}
+	return sum, total
+}
+
+/*
+	LogEvidence returns the sum of LogPosterior(label, x) over all labeled
+	features x in lfs, i.e. the log-likelihood of the labeled data including
+	the prior, if set, and not averaged. It is the L of AIC and BIC, and
+	compares competing models on the same data: higher is better.
+*/
+func (gc *GaussianClassifier) LogEvidence(lfs LabeledFeatureSet) float64 {
+	logL, _ := gc.totalLogLikelyhood(lfs)
+	return logL
+}
+
+/*
+	AIC returns the Akaike information criterion of gc on lfs,
+
+	  AIC = 2*p - 2*L,
+
+	where p is gc.NumParameters() and L is the log-likelihood of the labeled
+	features (with the prior, if set). Lower is better.
+*/
+func AIC(gc *GaussianClassifier, lfs LabeledFeatureSet) float64 {
+	logL, _ := gc.totalLogLikelyhood(lfs)
+	return 2*float64(gc.NumParameters()) - 2*logL
+}
+
+/*
+	BIC returns the Bayesian information criterion of gc on lfs,
+
+	  BIC = p*log(n) - 2*L,
+
+	where n is the number of features and p and L are as in AIC. Lower is
+	better.
+*/
+func BIC(gc *GaussianClassifier, lfs LabeledFeatureSet) float64 {
+	logL, n := gc.totalLogLikelyhood(lfs)
+	return float64(gc.NumParameters())*math.Log(float64(n)) - 2*logL
+}
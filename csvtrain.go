@@ -0,0 +1,73 @@
+package pr
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+/*
+	TrainGaussianFromCSV trains a *GaussianClassifier from CSV rows in r, read
+	one at a time into a GaussianAccumulator so that the whole dataset is never
+	held in memory. The column labelColumn (0-based) holds the label, an integer
+	in [0, n), and all other columns are the components of the feature. r must not
+	have a header line.
+
+	Errors on malformed rows contain the line number; an error of a label with
+	too few rows or a singular covariance matrix identifies the label.
+*/
+func TrainGaussianFromCSV(r io.Reader, labelColumn int) (*GaussianClassifier, error) {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+
+	var ga *GaussianAccumulator
+	var x []float64
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		lineNo, _ := cr.FieldPos(0)
+
+		if labelColumn < 0 || labelColumn >= len(record) {
+			return nil, fmt.Errorf("line %d: no label column %d in %d columns", lineNo, labelColumn, len(record))
+		}
+		if ga == nil {
+			/* encoding/csv ensures all rows have the same number of columns */
+			if ga, err = NewGaussianAccumulator(0, len(record)-1, 0); err != nil {
+				return nil, err
+			}
+			x = make([]float64, len(record)-1)
+		}
+
+		label, err := strconv.Atoi(record[labelColumn])
+		if err != nil || label < 0 {
+			return nil, fmt.Errorf("line %d: invalid label %q", lineNo, record[labelColumn])
+		}
+		k := 0
+		for col, field := range record {
+			if col == labelColumn {
+				continue
+			}
+			if x[k], err = strconv.ParseFloat(field, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid value %q in column %d", lineNo, field, col)
+			}
+			k++
+		}
+
+		ga.grow(label + 1)
+		if err := ga.Observe(label, x); err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNo, err)
+		}
+	}
+
+	if ga == nil {
+		return nil, errors.New("no rows")
+	}
+	return ga.Classifier()
+}
@@ -0,0 +1,322 @@
+package pr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+/*
+	A Metric scores a Classifier on a LabeledFeatureSet.
+*/
+type Metric func(c Classifier, lfs LabeledFeatureSet) float64
+
+var (
+	// AccuracyMetric is the fraction of correctly classified features.
+	AccuracyMetric Metric = Accuracy
+	// MacroF1Metric is the mean of the F1 scores of all labels.
+	MacroF1Metric Metric = func(c Classifier, lfs LabeledFeatureSet) float64 {
+		return BuildConfusionMatrix(c, lfs).MacroF1()
+	}
+	// CrossEntropyMetric is the mean cross-entropy of a ProbClassifier, lower is
+	// better. It is NaN for other classifiers.
+	CrossEntropyMetric Metric = func(c Classifier, lfs LabeledFeatureSet) float64 {
+		res := Evaluate(c, lfs)
+		if !res.HasCrossEntropy {
+			return math.NaN()
+		}
+		return res.CrossEntropy
+	}
+)
+
+/*
+	kFoldSplit returns the training and testing views of fold of a k-fold split
+	of lfs. The split is stratified: the i-th feature of every label is in fold
+	i % k.
+*/
+func kFoldSplit(lfs LabeledFeatureSet, k, fold int) (train, test LabeledFeatureSet) {
+	lblCnt := lfs.LabelCount()
+	trainIdx := make([][]int, lblCnt)
+	testIdx := make([][]int, lblCnt)
+	for lbl := 0; lbl < lblCnt; lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			if i%k == fold {
+				testIdx[lbl] = append(testIdx[lbl], i)
+			} else {
+				trainIdx[lbl] = append(trainIdx[lbl], i)
+			}
+		}
+	}
+	return &subsetSet{lfs, trainIdx}, &subsetSet{lfs, testIdx}
+}
+
+/*
+	CrossValidate runs a k-fold cross-validation of the classifiers trained by t
+	on lfs, and returns the mean and per-fold accuracies.
+*/
+func CrossValidate(t Trainer, lfs LabeledFeatureSet, k int) (float64, []float64, error) {
+	return CrossValidateMetric(t, lfs, k, AccuracyMetric)
+}
+
+/*
+	CrossValidateMetric is like CrossValidate but scores the folds with metric.
+	The folds are stratified: the i-th feature of every label is tested in fold
+	i % k.
+*/
+func CrossValidateMetric(t Trainer, lfs LabeledFeatureSet, k int, metric Metric) (float64, []float64, error) {
+	if k < 2 {
+		return 0, nil, errors.New("k must be at least 2")
+	}
+
+	scores := make([]float64, k)
+	mean := 0.
+	for fold := range scores {
+		score, err := crossValidateFold(t, lfs, k, fold, metric)
+		if err != nil {
+			return 0, nil, err
+		}
+		scores[fold] = score
+		mean += score
+	}
+
+	return mean / float64(k), scores, nil
+}
+
+/*
+	CrossValidateCI is like CrossValidate but returns the mean accuracy with a
+	normal-approximation 95% confidence interval, mean -/+ 1.96 standard errors,
+	where the standard error is the sample standard deviation of the per-fold
+	accuracies divided by sqrt(k).
+*/
+func CrossValidateCI(t Trainer, lfs LabeledFeatureSet, k int) (mean, low, high float64, err error) {
+	mean, scores, err := CrossValidate(t, lfs, k)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	ss := 0.
+	for _, score := range scores {
+		ss += (score - mean) * (score - mean)
+	}
+	stdErr := math.Sqrt(ss/float64(k-1)) / math.Sqrt(float64(k))
+	return mean, mean - 1.96*stdErr, mean + 1.96*stdErr, nil
+}
+
+func crossValidateFold(t Trainer, lfs LabeledFeatureSet, k, fold int, metric Metric) (float64, error) {
+	train, test := kFoldSplit(lfs, k, fold)
+	c := t.Train(train)
+	if c == nil {
+		return 0, fmt.Errorf("fold %d: training failed", fold)
+	}
+	return metric(c, test), nil
+}
+
+// labelOffsets returns the row in FeatureMatrix(lfs) of the first feature of
+// every label.
+func labelOffsets(lfs LabeledFeatureSet) []int {
+	offsets := make([]int, lfs.LabelCount())
+	total := 0
+	for lbl := range offsets {
+		offsets[lbl] = total
+		total += lfs.FeatureCount(lbl)
+	}
+	return offsets
+}
+
+/*
+	CrossValPredictProba returns, for every feature in lfs, the probabilities
+	given by a ProbClassifier trained by t on the other folds of a k-fold split
+	(the same stratified split as CrossValidate). The rows are aligned with
+	FeatureMatrix(lfs).
+*/
+func CrossValPredictProba(t Trainer, lfs LabeledFeatureSet, k int) ([][]float64, error) {
+	if k < 2 {
+		return nil, errors.New("k must be at least 2")
+	}
+
+	offsets := labelOffsets(lfs)
+	total := 0
+	for lbl := range offsets {
+		total += lfs.FeatureCount(lbl)
+	}
+	probs := make([][]float64, total)
+
+	x := make([]float64, lfs.Dim())
+	for fold := 0; fold < k; fold++ {
+		train, _ := kFoldSplit(lfs, k, fold)
+		pc, ok := t.Train(train).(ProbClassifier)
+		if !ok || pc == nil {
+			return nil, fmt.Errorf("fold %d: training failed or the classifier is not a ProbClassifier", fold)
+		}
+
+		for lbl := range offsets {
+			cnt := lfs.FeatureCount(lbl)
+			for i := fold; i < cnt; i += k {
+				lfs.FetchFeature(lbl, i, x)
+				probs[offsets[lbl]+i] = pc.ClassifyProb(x)
+			}
+		}
+	}
+
+	return probs, nil
+}
+
+/*
+	rowSplit returns the training and testing views of fold, where folds[row] is
+	the fold of the feature at row of FeatureMatrix(lfs).
+*/
+func rowSplit(lfs LabeledFeatureSet, folds []int, fold int) (train, test LabeledFeatureSet) {
+	lblCnt := lfs.LabelCount()
+	trainIdx := make([][]int, lblCnt)
+	testIdx := make([][]int, lblCnt)
+	row := 0
+	for lbl := 0; lbl < lblCnt; lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			if folds[row] == fold {
+				testIdx[lbl] = append(testIdx[lbl], i)
+			} else {
+				trainIdx[lbl] = append(trainIdx[lbl], i)
+			}
+			row++
+		}
+	}
+	return &subsetSet{lfs, trainIdx}, &subsetSet{lfs, testIdx}
+}
+
+/*
+	GroupKFold runs a k-fold cross-validation of the classifiers trained by t on
+	lfs where all features of a group are in the same fold, and returns the mean
+	accuracy. groups[row] is the group id of the feature at row of
+	FeatureMatrix(lfs).
+
+	Groups are assigned, largest first (ties by smaller id), to the fold with
+	the fewest features so far, so the folds are about the same size. There
+	must be at least k groups.
+*/
+func GroupKFold(t Trainer, lfs LabeledFeatureSet, groups []int, k int) (float64, error) {
+	if k < 2 {
+		return 0, errors.New("k must be at least 2")
+	}
+	total := 0
+	for lbl := 0; lbl < lfs.LabelCount(); lbl++ {
+		total += lfs.FeatureCount(lbl)
+	}
+	if len(groups) != total {
+		return 0, fmt.Errorf("%d groups but %d features", len(groups), total)
+	}
+
+	sizes := make(map[int]int)
+	var ids []int
+	for _, g := range groups {
+		if sizes[g] == 0 {
+			ids = append(ids, g)
+		}
+		sizes[g]++
+	}
+	if len(ids) < k {
+		return 0, fmt.Errorf("%d groups, at least k = %d needed", len(ids), k)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if sizes[ids[i]] != sizes[ids[j]] {
+			return sizes[ids[i]] > sizes[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+
+	foldOfGroup := make(map[int]int)
+	foldSizes := make([]int, k)
+	for _, g := range ids {
+		smallest := 0
+		for fold := range foldSizes {
+			if foldSizes[fold] < foldSizes[smallest] {
+				smallest = fold
+			}
+		}
+		foldOfGroup[g] = smallest
+		foldSizes[smallest] += sizes[g]
+	}
+	folds := make([]int, total)
+	for row, g := range groups {
+		folds[row] = foldOfGroup[g]
+	}
+
+	mean := 0.
+	for fold := 0; fold < k; fold++ {
+		train, test := rowSplit(lfs, folds, fold)
+		c := t.Train(train)
+		if c == nil {
+			return 0, fmt.Errorf("fold %d: training failed", fold)
+		}
+		mean += Accuracy(c, test)
+	}
+
+	return mean / float64(k), nil
+}
+
+/*
+	FoldIndices are the rows, in FeatureMatrix(lfs), of the training and the
+	testing features of one fold of a split of lfs.
+*/
+type FoldIndices struct {
+	Train []int
+	Test  []int
+}
+
+// rowsSet returns a view of lfs with the features at rows of FeatureMatrix(lfs).
+func rowsSet(lfs LabeledFeatureSet, rows []int) LabeledFeatureSet {
+	offsets := labelOffsets(lfs)
+	indices := make([][]int, len(offsets))
+	for _, row := range rows {
+		/*
+			the last label whose offset is at most row, which has features since
+			a label without features shares its offset with the next label
+		*/
+		lbl := sort.Search(len(offsets), func(l int) bool {
+			return offsets[l] > row
+		}) - 1
+		indices[lbl] = append(indices[lbl], row-offsets[lbl])
+	}
+	return &subsetSet{lfs, indices}
+}
+
+/*
+	Sets returns the training and the testing views of lfs of the fold.
+*/
+func (fi FoldIndices) Sets(lfs LabeledFeatureSet) (train, test LabeledFeatureSet) {
+	return rowsSet(lfs, fi.Train), rowsSet(lfs, fi.Test)
+}
+
+/*
+	KFoldSplits returns the k folds of a stratified split of lfs: the features
+	of every label are shuffled with seed, and the i-th of them is tested in
+	fold i % k. The rows in each FoldIndices are in ascending order.
+*/
+func KFoldSplits(lfs LabeledFeatureSet, k int, seed int64) ([]FoldIndices, error) {
+	if k < 2 {
+		return nil, errors.New("k must be at least 2")
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	var folds []int
+	for lbl := 0; lbl < lfs.LabelCount(); lbl++ {
+		for _, i := range rng.Perm(lfs.FeatureCount(lbl)) {
+			folds = append(folds, i%k)
+		}
+	}
+
+	splits := make([]FoldIndices, k)
+	for row, fold := range folds {
+		for f := range splits {
+			if f == fold {
+				splits[f].Test = append(splits[f].Test, row)
+			} else {
+				splits[f].Train = append(splits[f].Train, row)
+			}
+		}
+	}
+	return splits, nil
+}
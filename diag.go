@@ -0,0 +1,151 @@
+package pr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+/*
+	The trainer for a Gaussian classifier with diagonal covariance matrices,
+	a.k.a. the Gaussian naive Bayes classifier.
+*/
+type DiagonalGaussianTrainer struct {
+}
+
+/*
+	DiagonalGaussianTrain trains a *GaussianClassifier whose covariance matrices
+	are diagonal, i.e. the dimensions of the feature are assumed to be
+	independent given the label. The Diagonal and Counts fields of the result
+	are set. nil is returned if any variance is zero.
+*/
+func DiagonalGaussianTrain(lfs LabeledFeatureSet) *GaussianClassifier {
+	lblCnt := lfs.LabelCount()
+	dim := lfs.Dim()
+	clsfr := &GaussianClassifier{
+		Means:    make([][]float64, lblCnt),
+		Precs:    make([][]float64, lblCnt),
+		LogCoefs: make([]float64, lblCnt),
+		Diagonal: true,
+		Counts:   make([]int, lblCnt),
+	}
+
+	x := make([]float64, dim)
+
+	for lbl := range clsfr.Means {
+		cnt := lfs.FeatureCount(lbl)
+		mean := featureMean(lfs, lbl, x)
+
+		variance := make([]float64, dim)
+		for i := 0; i < cnt; i++ {
+			lfs.FetchFeature(lbl, i, x)
+			for k := range x {
+				v := x[k] - mean[k]
+				variance[k] += v * v
+			}
+		}
+
+		prec := make([]float64, dim*dim)
+		logDet := 0.
+		for k := range variance {
+			if cnt > 1 {
+				variance[k] /= float64(cnt - 1)
+			}
+			if !(variance[k] > 0) {
+				return nil
+			}
+			prec[k*dim+k] = -0.5 / variance[k]
+			logDet += math.Log(variance[k])
+		}
+
+		clsfr.Means[lbl] = mean
+		clsfr.Precs[lbl] = prec
+		clsfr.LogCoefs[lbl] = -0.5 * (math.Log(2.*math.Pi)*float64(dim) + logDet)
+		clsfr.Counts[lbl] = cnt
+	}
+
+	return clsfr
+}
+
+/*
+	PartialFitDiagonal updates the mean, the variances and the log-coefficient
+	of a label with one more feature x, as if the label was trained with x
+	added. gc must be a diagonal model with Counts, e.g. trained by
+	DiagonalGaussianTrain.
+*/
+func (gc *GaussianClassifier) PartialFitDiagonal(label int, x []float64) error {
+	if !gc.Diagonal || gc.Counts == nil {
+		return errors.New("not a diagonal model with counts")
+	}
+
+	mean := gc.Means[label]
+	prec := gc.Precs[label]
+	dim := len(mean)
+	if len(x) != dim {
+		return fmt.Errorf("feature has dimension %d, expected %d", len(x), dim)
+	}
+
+	cnt := gc.Counts[label]
+	newMean := make([]float64, dim)
+	variances := make([]float64, dim)
+	logDet := 0.
+	for k := range mean {
+		/* Welford's update of the mean and the sum of squared deviations */
+		m2 := 0.
+		if cnt > 1 {
+			m2 = -0.5 / prec[k*dim+k] * float64(cnt-1)
+		}
+		delta := x[k] - mean[k]
+		newMean[k] = mean[k] + delta/float64(cnt+1)
+		m2 += delta * (x[k] - newMean[k])
+
+		variances[k] = m2
+		if cnt > 0 {
+			variances[k] /= float64(cnt)
+		}
+		if !(variances[k] > 0) {
+			return fmt.Errorf("variance of dimension %d is zero", k)
+		}
+		logDet += math.Log(variances[k])
+	}
+
+	copy(mean, newMean)
+	for k, v := range variances {
+		prec[k*dim+k] = -0.5 / v
+	}
+	gc.Counts[label] = cnt + 1
+	gc.LogCoefs[label] = -0.5 * (math.Log(2.*math.Pi)*float64(dim) + logDet)
+
+	return nil
+}
+
+// Implementation of Trainer.Train. nil is returned if training fails.
+func (dgt *DiagonalGaussianTrainer) Train(lfs LabeledFeatureSet) Classifier {
+	if gc := DiagonalGaussianTrain(lfs); gc != nil {
+		return gc
+	}
+	return nil
+}
+
+/*
+	RefreshPriorsFromCounts sets the prior probabilities of all labels
+	proportional to Counts, e.g. after PartialFitDiagonal, so that the priors
+	follow the features seen so far. It requires Counts to be populated, e.g. by
+	DiagonalGaussianTrain, and does nothing if Counts is nil or all zeros. A
+	label with a zero count gets a zero prior.
+*/
+func (gc *GaussianClassifier) RefreshPriorsFromCounts() {
+	total := 0
+	for _, cnt := range gc.Counts {
+		total += cnt
+	}
+	if total == 0 {
+		return
+	}
+
+	priors := make([]float64, len(gc.Counts))
+	for lbl, cnt := range gc.Counts {
+		priors[lbl] = float64(cnt) / float64(total)
+	}
+	gc.SetPrior(priors)
+}
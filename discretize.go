@@ -0,0 +1,103 @@
+package pr
+
+import (
+	"errors"
+	"sort"
+)
+
+// Strategies of a Discretizer choosing bin edges.
+const (
+	// bins of equal width between the minimum and the maximum
+	EqualWidth = iota
+	// bins holding about the same number of training values
+	EqualFrequency
+)
+
+/*
+	A Discretizer maps every dimension of a continuous feature into bin indices,
+	so that the result can be used by models of categorical features, e.g.
+	CategoricalNBTrain with the cardinalities returned by Cardinalities.
+*/
+type Discretizer struct {
+	// EqualWidth or EqualFrequency
+	Strategy int
+	// Edges[k] are the ascending inner edges of the bins of dimension k. A
+	// value v is in bin i if Edges[k][i-1] <= v < Edges[k][i].
+	Edges [][]float64
+}
+
+/*
+	Fit computes the bin edges of every dimension from the features in lfs.
+*/
+func (d *Discretizer) Fit(lfs LabeledFeatureSet, bins int) error {
+	if bins < 1 {
+		return errors.New("bins must be positive")
+	}
+
+	dim := lfs.Dim()
+	values := make([][]float64, dim)
+	x := make([]float64, dim)
+	for lbl := 0; lbl < lfs.LabelCount(); lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			lfs.FetchFeature(lbl, i, x)
+			for k := range x {
+				values[k] = append(values[k], x[k])
+			}
+		}
+	}
+	if dim > 0 && len(values[0]) == 0 {
+		return errors.New("no features to fit")
+	}
+
+	d.Edges = make([][]float64, dim)
+	for k, vs := range values {
+		sort.Float64s(vs)
+		edges := make([]float64, bins-1)
+		for i := range edges {
+			if d.Strategy == EqualFrequency {
+				edges[i] = vs[(i+1)*len(vs)/bins]
+			} else {
+				min, max := vs[0], vs[len(vs)-1]
+				edges[i] = min + (max-min)*float64(i+1)/float64(bins)
+			}
+		}
+		d.Edges[k] = edges
+	}
+
+	return nil
+}
+
+/*
+	Cardinalities returns the number of bins of every dimension.
+*/
+func (d *Discretizer) Cardinalities() []int {
+	cards := make([]int, len(d.Edges))
+	for k := range d.Edges {
+		cards[k] = len(d.Edges[k]) + 1
+	}
+	return cards
+}
+
+/*
+	Transform returns the bin indices of every dimension of x, as float64s so
+	that the result is again a feature. Values out of the trained range fall
+	into the first or the last bin.
+*/
+func (d *Discretizer) Transform(x []float64) []float64 {
+	y := make([]float64, len(x))
+	for k, edges := range d.Edges {
+		y[k] = float64(sort.Search(len(edges), func(i int) bool {
+			return edges[i] > x[k]
+		}))
+	}
+	return y
+}
+
+/*
+	TransformSet returns a view of lfs with every feature transformed by
+	Transform.
+*/
+func (d *Discretizer) TransformSet(lfs LabeledFeatureSet) LabeledFeatureSet {
+	return newTransformedSet(lfs, len(d.Edges), d.Transform)
+}
@@ -0,0 +1,43 @@
+package pr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiscretizer(t *testing.T) {
+	sfs := &SliceFeatureSet{FeatureDim: 2, Features: [][][]float64{{}}}
+	for i, v := range []float64{0, 0, 0, 0, 1, 2, 10, 100} {
+		sfs.Features[0] = append(sfs.Features[0], []float64{float64(i), v})
+	}
+
+	for _, c := range []struct {
+		strategy int
+		edges    [][]float64
+		x, want  []float64
+	}{
+		{EqualWidth, [][]float64{{1.75, 3.5, 5.25}, {25, 50, 75}}, []float64{3.5, 50}, []float64{2, 2}},
+		{EqualWidth, [][]float64{{1.75, 3.5, 5.25}, {25, 50, 75}}, []float64{-5, 1000}, []float64{0, 3}},
+		/* the values at the indices 2, 4 and 6 of the sorted values */
+		{EqualFrequency, [][]float64{{2, 4, 6}, {0, 1, 10}}, []float64{1.9, 0}, []float64{0, 1}},
+		{EqualFrequency, [][]float64{{2, 4, 6}, {0, 1, 10}}, []float64{6, 9.9}, []float64{3, 2}},
+	} {
+		d := &Discretizer{Strategy: c.strategy}
+		if err := d.Fit(sfs, 4); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(d.Edges, c.edges) {
+			t.Errorf("strategy %d: edges %v, expected %v", c.strategy, d.Edges, c.edges)
+		}
+		if got := d.Transform(c.x); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("strategy %d: Transform(%v) = %v, expected %v", c.strategy, c.x, got, c.want)
+		}
+		if cards := d.Cardinalities(); !reflect.DeepEqual(cards, []int{4, 4}) {
+			t.Errorf("strategy %d: cardinalities %v, expected [4 4]", c.strategy, cards)
+		}
+	}
+
+	if err := (&Discretizer{}).Fit(sfs, 0); err == nil {
+		t.Error("expected an error for zero bins")
+	}
+}
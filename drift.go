@@ -0,0 +1,93 @@
+package pr
+
+import (
+	"math"
+)
+
+/*
+	A DriftMonitor detects a shift of the input distribution of a deployed
+	*GaussianClassifier. The drift score is the mean, over the observed inputs,
+	of the squared Mahalanobis distance to the nearest label, divided by the
+	same mean over the training features. It is about 1 while the inputs follow
+	the training distribution and grows as they drift away.
+
+	A DriftMonitor is not safe for concurrent use.
+*/
+type DriftMonitor struct {
+	// the model whose inputs are monitored
+	Model *GaussianClassifier
+	// the drift score above which Drifted reports true, e.g. 1.5
+	Threshold float64
+	// the mean distance of the training features
+	Reference float64
+
+	sum   float64
+	count int
+}
+
+// minMahalanobis returns the squared Mahalanobis distance of x to the
+// nearest label of gc.
+func minMahalanobis(gc *GaussianClassifier, x []float64) float64 {
+	best := math.Inf(1)
+	for lbl := range gc.Means {
+		/* LogLikelyhood(x) - LogCoef = -1/2 * squared Mahalanobis distance */
+		best = math.Min(best, -2.*(gc.LogLikelyhood(lbl, x)-gc.LogCoefs[lbl]))
+	}
+	return best
+}
+
+/*
+	NewDriftMonitor returns a *DriftMonitor of gc with the reference distance
+	computed on the training features lfs.
+*/
+func NewDriftMonitor(gc *GaussianClassifier, lfs LabeledFeatureSet, threshold float64) *DriftMonitor {
+	dm := &DriftMonitor{Model: gc, Threshold: threshold}
+	x := make([]float64, lfs.Dim())
+	sum, total := 0., 0
+	for lbl := 0; lbl < lfs.LabelCount(); lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			lfs.FetchFeature(lbl, i, x)
+			sum += minMahalanobis(gc, x)
+		}
+		total += cnt
+	}
+	if total > 0 {
+		dm.Reference = sum / float64(total)
+	}
+	return dm
+}
+
+/*
+	Observe adds a live input x to the running statistics.
+*/
+func (dm *DriftMonitor) Observe(x []float64) {
+	dm.sum += minMahalanobis(dm.Model, x)
+	dm.count++
+}
+
+/*
+	DriftScore returns the mean distance of the inputs observed so far divided
+	by the reference distance, or 0 if nothing is observed.
+*/
+func (dm *DriftMonitor) DriftScore() float64 {
+	if dm.count == 0 || dm.Reference == 0 {
+		return 0
+	}
+	return dm.sum / float64(dm.count) / dm.Reference
+}
+
+/*
+	Drifted returns true if DriftScore exceeds Threshold, i.e. retraining is
+	due.
+*/
+func (dm *DriftMonitor) Drifted() bool {
+	return dm.DriftScore() > dm.Threshold
+}
+
+/*
+	Reset clears the observed inputs, e.g. to monitor a new time window.
+*/
+func (dm *DriftMonitor) Reset() {
+	dm.sum, dm.count = 0, 0
+}
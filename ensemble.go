@@ -0,0 +1,116 @@
+package pr
+
+import (
+	"math"
+	"math/rand"
+)
+
+/*
+	A *WeightedEnsembleClassifier combines the probabilities of its members as
+	a weighted average.
+*/
+type WeightedEnsembleClassifier struct {
+	// the members
+	Members []ProbClassifier
+	// the nonnegative weights of the members, summing to 1
+	Weights []float64
+}
+
+// Implementation of ProbClassifier.ClassifyProb
+func (ec *WeightedEnsembleClassifier) ClassifyProb(x []float64) []float64 {
+	var probs []float64
+	for m, member := range ec.Members {
+		p := member.ClassifyProb(x)
+		if probs == nil {
+			probs = make([]float64, len(p))
+		}
+		for lbl := range p {
+			probs[lbl] += ec.Weights[m] * p[lbl]
+		}
+	}
+	return probs
+}
+
+// Implementation of Classifier.Classify
+func (ec *WeightedEnsembleClassifier) Classify(x []float64) int {
+	return argmax(ec.ClassifyProb(x))
+}
+
+// ensembleCrossEntropy returns the mean cross-entropy of the weighted average
+// of truthProbs, where truthProbs[m][i] is the probability given by member m to
+// the actual label of the i-th feature.
+func ensembleCrossEntropy(truthProbs [][]float64, weights []float64) float64 {
+	ce := 0.
+	for i := range truthProbs[0] {
+		p := 0.
+		for m, w := range weights {
+			p += w * truthProbs[m][i]
+		}
+		ce -= math.Log(math.Max(p, minProb))
+	}
+	return ce / float64(len(truthProbs[0]))
+}
+
+/*
+	LearnEnsembleWeights returns the nonnegative weights, summing to 1, of the
+	members that minimize the cross-entropy of their weighted average on lfs,
+	a validation set the members were not trained on.
+
+	Starting with equal weights, every iteration picks a member at random and
+	tries to shift some weight to or from it, keeping the change if the
+	cross-entropy decreases; the step is halved after an iteration without
+	improvement.
+*/
+func LearnEnsembleWeights(members []ProbClassifier, lfs LabeledFeatureSet, iterations int, seed int64) []float64 {
+	n := len(members)
+	weights := make([]float64, n)
+	for m := range weights {
+		weights[m] = 1. / float64(n)
+	}
+
+	/* the members are evaluated once */
+	truthProbs := make([][]float64, n)
+	x := make([]float64, lfs.Dim())
+	for lbl := 0; lbl < lfs.LabelCount(); lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			lfs.FetchFeature(lbl, i, x)
+			for m, member := range members {
+				truthProbs[m] = append(truthProbs[m], member.ClassifyProb(x)[lbl])
+			}
+		}
+	}
+	if n < 2 || len(truthProbs[0]) == 0 {
+		return weights
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	best := ensembleCrossEntropy(truthProbs, weights)
+	step := 0.5
+	trial := make([]float64, n)
+	for it := 0; it < iterations; it++ {
+		m := rng.Intn(n)
+		improved := false
+		for _, s := range []float64{step, -step} {
+			/* mixing with the vertex of m keeps the weights on the simplex */
+			if s < 0 && weights[m] < 1 {
+				s = math.Max(s, -weights[m]/(1-weights[m]))
+			}
+			for j := range trial {
+				trial[j] = (1 - s) * weights[j]
+			}
+			trial[m] += s
+			if ce := ensembleCrossEntropy(truthProbs, trial); ce < best {
+				best = ce
+				copy(weights, trial)
+				improved = true
+				break
+			}
+		}
+		if !improved {
+			step /= 2
+		}
+	}
+
+	return weights
+}
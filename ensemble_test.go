@@ -0,0 +1,52 @@
+package pr
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWeightedEnsembleClassifier(t *testing.T) {
+	ec := &WeightedEnsembleClassifier{
+		Members: []ProbClassifier{fixedProbs{1, 0}, fixedProbs{0.2, 0.8}},
+		Weights: []float64{0.25, 0.75},
+	}
+	probs := ec.ClassifyProb([]float64{0})
+	if want := []float64{0.4, 0.6}; !floatsEqual(probs, want, 1e-12) {
+		t.Errorf("ClassifyProb gives %v, expected %v", probs, want)
+	}
+	if lbl := ec.Classify([]float64{0}); lbl != 1 {
+		t.Errorf("Classify gives %d, expected 1", lbl)
+	}
+}
+
+func TestEnsembleCrossEntropy(t *testing.T) {
+	/* both averaged probabilities are 0.75 */
+	ce := ensembleCrossEntropy([][]float64{{0.5, 1}, {1, 0.5}}, []float64{0.5, 0.5})
+	if want := -math.Log(0.75); math.Abs(ce-want) > 1e-12 {
+		t.Errorf("cross-entropy %v, expected %v", ce, want)
+	}
+}
+
+func TestLearnEnsembleWeights(t *testing.T) {
+	sfs := &SliceFeatureSet{
+		FeatureDim: 1,
+		Features:   [][][]float64{{{0}, {0}, {0}}, {{0}}},
+	}
+	members := []ProbClassifier{fixedProbs{0.8, 0.2}, fixedProbs{0.2, 0.8}}
+	/*
+		The averaged probability of label 0 is 0.2+0.6*w[0], and the
+		cross-entropy on three features of label 0 and one of label 1 is minimal
+		when it is 0.75, i.e. w[0] = 0.55/0.6.
+	*/
+	weights := LearnEnsembleWeights(members, sfs, 200, 1)
+	if want := []float64{0.55 / 0.6, 0.05 / 0.6}; !floatsEqual(weights, want, 1e-3) {
+		t.Errorf("weights %v, expected %v", weights, want)
+	}
+	if sum := weights[0] + weights[1]; math.Abs(sum-1) > 1e-12 {
+		t.Errorf("weights sum to %v, expected 1", sum)
+	}
+
+	if weights := LearnEnsembleWeights(members[:1], sfs, 10, 1); !floatsEqual(weights, []float64{1}, 0) {
+		t.Errorf("single member weights %v, expected [1]", weights)
+	}
+}
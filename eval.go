@@ -0,0 +1,442 @@
+package pr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/*
+	ConfusionMatrix counts the classification results of a Classifier on a
+	LabeledFeatureSet.
+
+	Rows and columns are ordered by label 0..N-1, as are all per-label results
+	of the evaluators in this package; none of them depends on the iteration
+	order of a map, so the same inputs always give the same String output.
+*/
+type ConfusionMatrix struct {
+	// Counts[actual][predicted] is the number of features of label actual that
+	// are classified as label predicted.
+	Counts [][]int
+	// if non-nil, Rejected[actual] is the number of features of label actual
+	// that are rejected, i.e. classified as RejectLabel
+	Rejected []int
+}
+
+/*
+	NewConfusionMatrix returns an all-zero ConfusionMatrix for labelCount labels.
+*/
+func NewConfusionMatrix(labelCount int) ConfusionMatrix {
+	counts := make([][]int, labelCount)
+	for i := range counts {
+		counts[i] = make([]int, labelCount)
+	}
+	return ConfusionMatrix{Counts: counts}
+}
+
+/*
+	BuildConfusionMatrix classifies all features in lfs with c and returns the
+	resulting ConfusionMatrix. Features classified as RejectLabel are counted
+	in Rejected instead of Counts.
+*/
+func BuildConfusionMatrix(c Classifier, lfs LabeledFeatureSet) ConfusionMatrix {
+	cm := NewConfusionMatrix(lfs.LabelCount())
+
+	x := make([]float64, lfs.Dim())
+	for lbl := range cm.Counts {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			lfs.FetchFeature(lbl, i, x)
+			cm.add(lbl, c.Classify(x))
+		}
+	}
+
+	return cm
+}
+
+// add counts a feature of label actual classified as predicted, which may be
+// RejectLabel.
+func (cm *ConfusionMatrix) add(actual, predicted int) {
+	if predicted != RejectLabel {
+		cm.Counts[actual][predicted]++
+		return
+	}
+	if cm.Rejected == nil {
+		cm.Rejected = make([]int, cm.LabelCount())
+	}
+	cm.Rejected[actual]++
+}
+
+/*
+	RejectedCounts returns the number of rejected features of every label, all
+	zeros if nothing is rejected.
+*/
+func (cm ConfusionMatrix) RejectedCounts() []int {
+	counts := make([]int, cm.LabelCount())
+	copy(counts, cm.Rejected)
+	return counts
+}
+
+/*
+	RejectionRate returns the fraction of rejected features among all features,
+	classified or rejected, or 0 if there are none.
+*/
+func (cm ConfusionMatrix) RejectionRate() float64 {
+	rejected := cm.rejectedTotal()
+	if rejected == 0 {
+		return 0
+	}
+	return float64(rejected) / float64(cm.Total()+rejected)
+}
+
+// rejectedTotal returns the number of rejected features.
+func (cm ConfusionMatrix) rejectedTotal() int {
+	rejected := 0
+	for _, c := range cm.Rejected {
+		rejected += c
+	}
+	return rejected
+}
+
+// LabelCount returns the number of labels.
+func (cm ConfusionMatrix) LabelCount() int {
+	return len(cm.Counts)
+}
+
+// Total returns the total number of classified features, excluding rejected
+// ones.
+func (cm ConfusionMatrix) Total() int {
+	total := 0
+	for _, row := range cm.Counts {
+		for _, c := range row {
+			total += c
+		}
+	}
+	return total
+}
+
+/*
+	Accuracy returns the fraction of correctly classified features among all
+	features, or 0 if cm is empty. Rejected features count as errors, as in
+	AccuracyParallel and WeightedAccuracy; AccuracyCoverage gives the accuracy
+	on the accepted features only, for a ProbClassifier with a threshold.
+*/
+func (cm ConfusionMatrix) Accuracy() float64 {
+	total := cm.Total() + cm.rejectedTotal()
+	if total == 0 {
+		return 0
+	}
+	correct := 0
+	for lbl := range cm.Counts {
+		correct += cm.Counts[lbl][lbl]
+	}
+	return float64(correct) / float64(total)
+}
+
+/*
+	Precision returns the fraction of features classified as label that are
+	actually of label, or 0 if no feature is classified as label.
+*/
+func (cm ConfusionMatrix) Precision(label int) float64 {
+	predicted := 0
+	for _, row := range cm.Counts {
+		predicted += row[label]
+	}
+	if predicted == 0 {
+		return 0
+	}
+	return float64(cm.Counts[label][label]) / float64(predicted)
+}
+
+/*
+	Recall returns the fraction of features of label that are classified as
+	label, or 0 if there is no feature of label.
+*/
+func (cm ConfusionMatrix) Recall(label int) float64 {
+	actual := 0
+	for _, c := range cm.Counts[label] {
+		actual += c
+	}
+	if actual == 0 {
+		return 0
+	}
+	return float64(cm.Counts[label][label]) / float64(actual)
+}
+
+/*
+	F1 returns the harmonic mean of the precision and the recall of label, or 0
+	if both are 0.
+*/
+func (cm ConfusionMatrix) F1(label int) float64 {
+	p, r := cm.Precision(label), cm.Recall(label)
+	if p+r == 0 {
+		return 0
+	}
+	return 2 * p * r / (p + r)
+}
+
+// MacroF1 returns the mean of F1 over all labels.
+func (cm ConfusionMatrix) MacroF1() float64 {
+	if cm.LabelCount() == 0 {
+		return 0
+	}
+	sum := 0.
+	for lbl := range cm.Counts {
+		sum += cm.F1(lbl)
+	}
+	return sum / float64(cm.LabelCount())
+}
+
+/*
+	Normalize returns the rates of the counts. With axis 0 every row is divided
+	by its sum, i.e. the recall view, and with axis 1 every column is divided by
+	its sum, i.e. the precision view. Rows or columns summing to zero give zeros.
+	nil is returned for other axes. cm is not changed.
+*/
+func (cm ConfusionMatrix) Normalize(axis int) [][]float64 {
+	if axis != 0 && axis != 1 {
+		return nil
+	}
+
+	n := cm.LabelCount()
+	sums := make([]int, n)
+	for actual, row := range cm.Counts {
+		for predicted, c := range row {
+			if axis == 0 {
+				sums[actual] += c
+			} else {
+				sums[predicted] += c
+			}
+		}
+	}
+
+	rates := make([][]float64, n)
+	for actual, row := range cm.Counts {
+		rates[actual] = make([]float64, n)
+		for predicted, c := range row {
+			sum := sums[actual]
+			if axis == 1 {
+				sum = sums[predicted]
+			}
+			if sum > 0 {
+				rates[actual][predicted] = float64(c) / float64(sum)
+			}
+		}
+	}
+	return rates
+}
+
+/*
+	Accuracy returns the fraction of features in lfs that are correctly
+	classified by c. Features c classifies as RejectLabel count as errors.
+*/
+func Accuracy(c Classifier, lfs LabeledFeatureSet) float64 {
+	return BuildConfusionMatrix(c, lfs).Accuracy()
+}
+
+// probabilities are clamped to this value when computing logarithms
+const minProb = 1e-15
+
+/*
+	EvalResult is the evaluation result of a Classifier on a LabeledFeatureSet.
+	The accuracy and per-label metrics are available through the methods of
+	the embedded ConfusionMatrix.
+*/
+type EvalResult struct {
+	ConfusionMatrix
+	// true if the classifier is a ProbClassifier and CrossEntropy is set
+	HasCrossEntropy bool
+	// the mean of -log(p) over all features, where p is the probability of the
+	// actual label given by the ProbClassifier
+	CrossEntropy float64
+}
+
+/*
+	Evaluate computes the ConfusionMatrix of c on lfs, and the cross-entropy if
+	c is a ProbClassifier, in a single pass over lfs.
+*/
+func Evaluate(c Classifier, lfs LabeledFeatureSet) EvalResult {
+	res := EvalResult{ConfusionMatrix: NewConfusionMatrix(lfs.LabelCount())}
+	pc, isProb := c.(ProbClassifier)
+
+	x := make([]float64, lfs.Dim())
+	total := 0
+	for lbl := range res.Counts {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			lfs.FetchFeature(lbl, i, x)
+			res.add(lbl, c.Classify(x))
+			if isProb {
+				res.CrossEntropy -= math.Log(math.Max(pc.ClassifyProb(x)[lbl], minProb))
+			}
+		}
+		total += cnt
+	}
+
+	if isProb && total > 0 {
+		res.HasCrossEntropy = true
+		res.CrossEntropy /= float64(total)
+	}
+
+	return res
+}
+
+/*
+	String renders the ConfusionMatrix as an aligned text table. Rows are actual
+	labels, columns are predicted labels, and the last column is the total of
+	each row.
+*/
+func (cm ConfusionMatrix) String() string {
+	return cm.StringNamed(nil)
+}
+
+/*
+	StringNamed is like String but uses names[label] as the header of a label.
+	Labels beyond the end of names keep their numeric headers.
+*/
+func (cm ConfusionMatrix) StringNamed(names []string) string {
+	n := cm.LabelCount()
+	headers := make([]string, n)
+	for lbl := range headers {
+		if lbl < len(names) {
+			headers[lbl] = names[lbl]
+		} else {
+			headers[lbl] = strconv.Itoa(lbl)
+		}
+	}
+
+	totals := make([]int, n)
+	rowWidth := 0
+	colWidth := len("total")
+	for lbl, row := range cm.Counts {
+		for _, c := range row {
+			totals[lbl] += c
+		}
+		if len(headers[lbl]) > rowWidth {
+			rowWidth = len(headers[lbl])
+		}
+		if len(headers[lbl]) > colWidth {
+			colWidth = len(headers[lbl])
+		}
+		if w := len(strconv.Itoa(totals[lbl])); w > colWidth {
+			colWidth = w
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%*s", rowWidth, "")
+	for _, h := range headers {
+		fmt.Fprintf(&b, " %*s", colWidth, h)
+	}
+	fmt.Fprintf(&b, " %*s\n", colWidth, "total")
+	for lbl, row := range cm.Counts {
+		fmt.Fprintf(&b, "%-*s", rowWidth, headers[lbl])
+		for _, c := range row {
+			fmt.Fprintf(&b, " %*d", colWidth, c)
+		}
+		fmt.Fprintf(&b, " %*d\n", colWidth, totals[lbl])
+	}
+
+	return b.String()
+}
+
+/*
+	A MetricAccumulator maintains a ConfusionMatrix incrementally from a stream
+	of predictions, for evaluation without re-traversing the data. The zero
+	value is ready to use; the matrix grows as larger labels are observed. It
+	is not safe for concurrent use.
+*/
+type MetricAccumulator struct {
+	cm ConfusionMatrix
+}
+
+/*
+	Observe records a prediction of label predicted, or RejectLabel, for a
+	feature of label actual.
+*/
+func (ma *MetricAccumulator) Observe(predicted, actual int) {
+	if predicted == RejectLabel {
+		ma.grow(actual + 1)
+		ma.cm.add(actual, predicted)
+		return
+	}
+	if predicted > actual {
+		ma.grow(predicted + 1)
+	} else {
+		ma.grow(actual + 1)
+	}
+	ma.cm.add(actual, predicted)
+}
+
+func (ma *MetricAccumulator) grow(labelCount int) {
+	if labelCount <= ma.cm.LabelCount() {
+		return
+	}
+	cm := NewConfusionMatrix(labelCount)
+	for i, row := range ma.cm.Counts {
+		copy(cm.Counts[i], row)
+	}
+	if ma.cm.Rejected != nil {
+		cm.Rejected = make([]int, labelCount)
+		copy(cm.Rejected, ma.cm.Rejected)
+	}
+	ma.cm = cm
+}
+
+// Accuracy returns the accuracy of the predictions observed so far.
+func (ma *MetricAccumulator) Accuracy() float64 {
+	return ma.cm.Accuracy()
+}
+
+// MacroF1 returns the macro F1 score of the predictions observed so far.
+func (ma *MetricAccumulator) MacroF1() float64 {
+	return ma.cm.MacroF1()
+}
+
+/*
+	ConfusionMatrix returns a copy of the ConfusionMatrix of the predictions
+	observed so far.
+*/
+func (ma *MetricAccumulator) ConfusionMatrix() ConfusionMatrix {
+	cm := NewConfusionMatrix(ma.cm.LabelCount())
+	for i, row := range ma.cm.Counts {
+		copy(cm.Counts[i], row)
+	}
+	if ma.cm.Rejected != nil {
+		cm.Rejected = append([]int(nil), ma.cm.Rejected...)
+	}
+	return cm
+}
+
+/*
+	A Disagreement is a feature on which two classifiers disagree. See
+	Disagreements.
+*/
+type Disagreement struct {
+	// the true label of the feature
+	Label int
+	// the index of the feature within its label
+	Index int
+	// the predictions of the two classifiers
+	PredictedA, PredictedB int
+}
+
+/*
+	Disagreements returns the features of lfs that a and b classify differently,
+	ordered by label and then by index.
+*/
+func Disagreements(a, b Classifier, lfs LabeledFeatureSet) []Disagreement {
+	var ds []Disagreement
+	x := make([]float64, lfs.Dim())
+	for lbl := 0; lbl < lfs.LabelCount(); lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			lfs.FetchFeature(lbl, i, x)
+			if pa, pb := a.Classify(x), b.Classify(x); pa != pb {
+				ds = append(ds, Disagreement{lbl, i, pa, pb})
+			}
+		}
+	}
+	return ds
+}
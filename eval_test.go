@@ -0,0 +1,90 @@
+package pr
+
+import (
+	"testing"
+)
+
+func TestConfusionMatrixStringDeterministic(t *testing.T) {
+	sfs := randomSet(1, 100, []float64{0, 0}, []float64{1, 1}, []float64{2, 0})
+	gc, err := (&GaussianTrainer{}).TrainGaussian(sfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := BuildConfusionMatrix(gc, sfs).String()
+	for i := 0; i < 20; i++ {
+		if got := BuildConfusionMatrix(gc, sfs).String(); got != want {
+			t.Fatalf("run %d: String gives\n%s\nexpected\n%s", i, got, want)
+		}
+		if got := Evaluate(gc, sfs).String(); got != want {
+			t.Fatalf("run %d: String of Evaluate gives\n%s\nexpected\n%s", i, got, want)
+		}
+	}
+
+	var ma MetricAccumulator
+	ma.Observe(1, 0)
+	ma.Observe(0, 0)
+	ma.Observe(2, 1)
+	ma.Observe(1, 1)
+	ma.Observe(1, 1)
+	const table = "" +
+		"      0     1     2 total\n" +
+		"0     1     1     0     2\n" +
+		"1     0     2     1     3\n" +
+		"2     0     0     0     0\n"
+	for i := 0; i < 20; i++ {
+		if got := ma.ConfusionMatrix().String(); got != table {
+			t.Fatalf("run %d: String gives\n%s\nexpected\n%s", i, got, table)
+		}
+	}
+}
+
+func TestConfusionMatrixStringNamedMissingNames(t *testing.T) {
+	cm := ConfusionMatrix{Counts: [][]int{{1, 0, 0}, {0, 2, 0}, {0, 1, 3}}}
+	const want = "" +
+		"      cat   dog     2 total\n" +
+		"cat     1     0     0     1\n" +
+		"dog     0     2     0     2\n" +
+		"2       0     1     3     4\n"
+	if got := cm.StringNamed([]string{"cat", "dog"}); got != want {
+		t.Errorf("StringNamed gives\n%s\nexpected\n%s", got, want)
+	}
+}
+
+// signClassifier classifies x as 1 if x[0] > 1, as 0 if x[0] < -1, and
+// rejects it otherwise.
+type signClassifier struct{}
+
+// Implementation of Classifier.Classify
+func (signClassifier) Classify(x []float64) int {
+	switch {
+	case x[0] > 1:
+		return 1
+	case x[0] < -1:
+		return 0
+	}
+	return RejectLabel
+}
+
+func TestAccuracyCountsRejectsAsErrors(t *testing.T) {
+	/* 3 correct, 1 wrong and 2 rejected of 6 features */
+	sfs := &SliceFeatureSet{
+		FeatureDim: 1,
+		Features:   [][][]float64{{{-2}, {-3}, {0}}, {{2}, {-2}, {0.5}}},
+	}
+	const want = 3. / 6
+	if acc := Accuracy(signClassifier{}, sfs); acc != want {
+		t.Errorf("Accuracy gives %v, expected %v", acc, want)
+	}
+	for _, workers := range []int{1, 2, 4} {
+		if acc := AccuracyParallel(signClassifier{}, sfs, workers); acc != want {
+			t.Errorf("AccuracyParallel with %d workers gives %v, expected %v", workers, acc, want)
+		}
+	}
+	if acc := WeightedAccuracy(signClassifier{}, sfs); acc != want {
+		t.Errorf("WeightedAccuracy gives %v, expected %v", acc, want)
+	}
+	if rate := BuildConfusionMatrix(signClassifier{}, sfs).RejectionRate(); rate != 2./6 {
+		t.Errorf("RejectionRate gives %v, expected %v", rate, 2./6)
+	}
+}
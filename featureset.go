@@ -0,0 +1,198 @@
+package pr
+
+import (
+	"sync"
+)
+
+/*
+	SliceFeatureSet is a LabeledFeatureSet stored in memory.
+*/
+type SliceFeatureSet struct {
+	// the dimension of features
+	FeatureDim int
+	// Features[label][index] is the index-th feature of label
+	Features [][][]float64
+}
+
+// Implementation of LabeledFeatureSet.Dim
+func (sfs *SliceFeatureSet) Dim() int {
+	return sfs.FeatureDim
+}
+
+// Implementation of LabeledFeatureSet.LabelCount
+func (sfs *SliceFeatureSet) LabelCount() int {
+	return len(sfs.Features)
+}
+
+// Implementation of LabeledFeatureSet.FeatureCount
+func (sfs *SliceFeatureSet) FeatureCount(label int) int {
+	return len(sfs.Features[label])
+}
+
+// Implementation of LabeledFeatureSet.FetchFeature
+func (sfs *SliceFeatureSet) FetchFeature(label, index int, x []float64) {
+	copy(x, sfs.Features[label][index])
+}
+
+/*
+	transformedSet is a view of a LabeledFeatureSet with every feature passed
+	through a transform.
+*/
+type transformedSet struct {
+	lfs       LabeledFeatureSet
+	dim       int
+	transform func(x []float64) []float64
+}
+
+func newTransformedSet(lfs LabeledFeatureSet, dim int, transform func(x []float64) []float64) *transformedSet {
+	return &transformedSet{
+		lfs:       lfs,
+		dim:       dim,
+		transform: transform,
+	}
+}
+
+// Implementation of LabeledFeatureSet.Dim
+func (ts *transformedSet) Dim() int {
+	return ts.dim
+}
+
+// Implementation of LabeledFeatureSet.LabelCount
+func (ts *transformedSet) LabelCount() int {
+	return ts.lfs.LabelCount()
+}
+
+// Implementation of LabeledFeatureSet.FeatureCount
+func (ts *transformedSet) FeatureCount(label int) int {
+	return ts.lfs.FeatureCount(label)
+}
+
+// Implementation of LabeledFeatureSet.FetchFeature. It is safe for concurrent
+// use if lfs is.
+func (ts *transformedSet) FetchFeature(label, index int, x []float64) {
+	buf := make([]float64, ts.lfs.Dim())
+	ts.lfs.FetchFeature(label, index, buf)
+	copy(x, ts.transform(buf))
+}
+
+/*
+	subsetSet is a view of a LabeledFeatureSet containing, for each label, the
+	features at the specified indices.
+*/
+type subsetSet struct {
+	lfs LabeledFeatureSet
+	// indices[label] are the indices, in lfs, of the features of label
+	indices [][]int
+}
+
+// Implementation of LabeledFeatureSet.Dim
+func (ss *subsetSet) Dim() int {
+	return ss.lfs.Dim()
+}
+
+// Implementation of LabeledFeatureSet.LabelCount
+func (ss *subsetSet) LabelCount() int {
+	return len(ss.indices)
+}
+
+// Implementation of LabeledFeatureSet.FeatureCount
+func (ss *subsetSet) FeatureCount(label int) int {
+	return len(ss.indices[label])
+}
+
+// Implementation of LabeledFeatureSet.FetchFeature
+func (ss *subsetSet) FetchFeature(label, index int, x []float64) {
+	ss.lfs.FetchFeature(label, ss.indices[label][index], x)
+}
+
+/*
+	cachedSet is a view of a LabeledFeatureSet keeping every feature in memory
+	once fetched.
+*/
+type cachedSet struct {
+	lfs LabeledFeatureSet
+	mu  sync.Mutex
+	// features[label][index] is the cached feature, nil if not fetched yet
+	features [][][]float64
+}
+
+/*
+	Cache returns a view of lfs that fetches every feature from lfs only on its
+	first access and serves later fetches from memory, which saves repeated work
+	of an expensive lfs, e.g. one reading features from disk, in training and
+	cross-validation. The cache grows to at most the size of lfs. The view is
+	safe for concurrent use if lfs is.
+*/
+func Cache(lfs LabeledFeatureSet) LabeledFeatureSet {
+	cs := &cachedSet{
+		lfs:      lfs,
+		features: make([][][]float64, lfs.LabelCount()),
+	}
+	for lbl := range cs.features {
+		cs.features[lbl] = make([][]float64, lfs.FeatureCount(lbl))
+	}
+	return cs
+}
+
+// Implementation of LabeledFeatureSet.Dim
+func (cs *cachedSet) Dim() int {
+	return cs.lfs.Dim()
+}
+
+// Implementation of LabeledFeatureSet.LabelCount
+func (cs *cachedSet) LabelCount() int {
+	return len(cs.features)
+}
+
+// Implementation of LabeledFeatureSet.FeatureCount
+func (cs *cachedSet) FeatureCount(label int) int {
+	return len(cs.features[label])
+}
+
+// Implementation of LabeledFeatureSet.FetchFeature
+func (cs *cachedSet) FetchFeature(label, index int, x []float64) {
+	cs.mu.Lock()
+	f := cs.features[label][index]
+	cs.mu.Unlock()
+
+	if f == nil {
+		/* fetched outside the lock; a concurrent fetch of it just repeats work */
+		f = make([]float64, cs.lfs.Dim())
+		cs.lfs.FetchFeature(label, index, f)
+		cs.mu.Lock()
+		cs.features[label][index] = f
+		cs.mu.Unlock()
+	}
+	copy(x, f)
+}
+
+/*
+	FeatureMatrix returns all features in lfs as rows, ordered by label and then
+	by index within the label. FeatureLabels gives the labels of the rows.
+*/
+func FeatureMatrix(lfs LabeledFeatureSet) [][]float64 {
+	var rows [][]float64
+	for lbl := 0; lbl < lfs.LabelCount(); lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			x := make([]float64, lfs.Dim())
+			lfs.FetchFeature(lbl, i, x)
+			rows = append(rows, x)
+		}
+	}
+	return rows
+}
+
+/*
+	FeatureLabels returns the labels of the rows of FeatureMatrix(lfs).
+*/
+func FeatureLabels(lfs LabeledFeatureSet) []int {
+	var labels []int
+	for lbl := 0; lbl < lfs.LabelCount(); lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			labels = append(labels, lbl)
+		}
+	}
+	return labels
+}
@@ -1,8 +1,12 @@
 package pr
 
 import (
+	"errors"
+	"fmt"
 	"github.com/skelterjohn/go.matrix"
 	"math"
+	"math/rand"
+	"strings"
 )
 
 /*
@@ -28,6 +32,66 @@ type GaussianClassifier struct {
 	LogCoefs []float64
 	// if non-nil, the logarithm of prior priorities
 	LogPrior []float64
+	// true if all Precs are diagonal, e.g. trained by DiagonalGaussianTrain
+	Diagonal bool
+	// if non-nil, the number of features each label was trained on, used by
+	// PartialFitDiagonal
+	Counts []int
+	// if non-nil, the weights of the dimensions in LogLikelyhood. See
+	// SetFeatureWeights
+	FeatureWeights []float64
+	// if non-nil, the shrinkage intensity of the covariance matrix of every
+	// label, set by LedoitWolfGaussianTrain
+	Shrinkages []float64
+}
+
+// LabelCount returns the number of labels.
+func (gc *GaussianClassifier) LabelCount() int {
+	return len(gc.LogCoefs)
+}
+
+// Dim returns the dimension of the feature.
+func (gc *GaussianClassifier) Dim() int {
+	if len(gc.Means) == 0 {
+		return 0
+	}
+	return len(gc.Means[0])
+}
+
+// at most this number of components of a vector are shown by String
+const stringMaxComponents = 5
+
+/*
+	String returns a readable summary of the model: the number of labels, the
+	dimension, and the mean (only the first few components for high
+	dimensions), the log-coefficient and the prior (if set) of every label.
+*/
+func (gc *GaussianClassifier) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "GaussianClassifier: %d labels, dim %d", gc.LabelCount(), gc.Dim())
+	if gc.Diagonal {
+		b.WriteString(", diagonal")
+	}
+	for lbl := range gc.LogCoefs {
+		fmt.Fprintf(&b, "\n  label %d: mean [", lbl)
+		for k, m := range gc.Means[lbl] {
+			if k == stringMaxComponents {
+				b.WriteString(" ...")
+				break
+			}
+			if k > 0 {
+				b.WriteString(" ")
+			}
+			fmt.Fprintf(&b, "%.4g", m)
+		}
+		fmt.Fprintf(&b, "], logCoef %.4g", gc.LogCoefs[lbl])
+		if gc.LogPrior != nil {
+			fmt.Fprintf(&b, ", prior %.4g", math.Exp(gc.LogPrior[lbl]))
+		}
+	}
+
+	return b.String()
 }
 
 /*
@@ -42,7 +106,151 @@ func (gc *GaussianClassifier) SetPrior(priors []float64) {
 	}
 }
 
-// Implementation of Classifier.Classify
+/*
+	Clone returns a deep copy of gc which shares no slices with gc.
+*/
+func (gc *GaussianClassifier) Clone() *GaussianClassifier {
+	c := *gc
+	c.Means = cloneSlices(gc.Means)
+	c.Precs = cloneSlices(gc.Precs)
+	c.LogCoefs = append([]float64(nil), gc.LogCoefs...)
+	if gc.LogPrior != nil {
+		c.LogPrior = append([]float64(nil), gc.LogPrior...)
+	}
+	if gc.Counts != nil {
+		c.Counts = append([]int(nil), gc.Counts...)
+	}
+	if gc.FeatureWeights != nil {
+		c.FeatureWeights = append([]float64(nil), gc.FeatureWeights...)
+	}
+	if gc.Shrinkages != nil {
+		c.Shrinkages = append([]float64(nil), gc.Shrinkages...)
+	}
+	return &c
+}
+
+/*
+	Equal returns true if gc and other have the same shape and all of Means,
+	Precs, LogCoefs and LogPrior agree within the absolute tolerance tol. A nil
+	LogPrior only equals a nil LogPrior.
+*/
+func (gc *GaussianClassifier) Equal(other *GaussianClassifier, tol float64) bool {
+	if (gc.LogPrior == nil) != (other.LogPrior == nil) || gc.Diagonal != other.Diagonal {
+		return false
+	}
+	return slicesEqual(gc.Means, other.Means, tol) &&
+		slicesEqual(gc.Precs, other.Precs, tol) &&
+		floatsEqual(gc.LogCoefs, other.LogCoefs, tol) &&
+		floatsEqual(gc.LogPrior, other.LogPrior, tol)
+}
+
+/*
+	LabelMean returns a copy of the mean of a label. Modifying the result does
+	not affect gc.
+*/
+func (gc *GaussianClassifier) LabelMean(label int) []float64 {
+	return append([]float64(nil), gc.Means[label]...)
+}
+
+/*
+	AllMeans returns a deep copy of the means of all labels. Modifying the
+	result does not affect gc.
+*/
+func (gc *GaussianClassifier) AllMeans() [][]float64 {
+	return cloneSlices(gc.Means)
+}
+
+/*
+	SetFeatureWeights sets nonnegative weights of the dimensions of the
+	feature. The entry (k, l) of the quadratic term of LogLikelyhood is scaled
+	by sqrt(weights[k]*weights[l]), so a dimension with a small weight affects
+	the classification less. A nil weights removes the weighting.
+*/
+func (gc *GaussianClassifier) SetFeatureWeights(weights []float64) error {
+	if weights != nil {
+		if len(weights) != gc.Dim() {
+			return fmt.Errorf("%d weights for dimension %d", len(weights), gc.Dim())
+		}
+		for k, w := range weights {
+			if !(w >= 0) {
+				return fmt.Errorf("weight %d is %v, expected nonnegative", k, w)
+			}
+		}
+	}
+	gc.FeatureWeights = weights
+	return nil
+}
+
+/*
+	weightedPrec returns prec with the feature weights folded in, i.e. the
+	entry (k, l) scaled by sqrt(w_k*w_l). prec itself is returned if no weights
+	are set.
+*/
+func (gc *GaussianClassifier) weightedPrec(prec []float64) []float64 {
+	if gc.FeatureWeights == nil {
+		return prec
+	}
+	dim := len(gc.FeatureWeights)
+	wp := make([]float64, len(prec))
+	for k := 0; k < dim; k++ {
+		for l := 0; l < dim; l++ {
+			wp[k*dim+l] = prec[k*dim+l] * math.Sqrt(gc.FeatureWeights[k]*gc.FeatureWeights[l])
+		}
+	}
+	return wp
+}
+
+// Strategies of ClassifyTieBreak choosing among labels with equal posteriors.
+const (
+	// the lowest label, which is also the first one in label order
+	TieLowestLabel = iota
+	// the label with the highest prior, the lowest label among equal priors
+	TieHighestPrior
+	// a label chosen uniformly at random
+	TieRandom
+)
+
+/*
+	ClassifyTieBreak is like Classify but chooses among labels with exactly
+	equal posteriors by strategy. rng is used by TieRandom only; if it is nil,
+	TieRandom falls back to TieLowestLabel.
+*/
+func (gc *GaussianClassifier) ClassifyTieBreak(x []float64, strategy int, rng *rand.Rand) int {
+	bestLogP := 0.
+	var ties []int
+
+	for lbl := range gc.LogCoefs {
+		logP := gc.LogPosterior(lbl, x)
+
+		switch {
+		case len(ties) == 0 || logP > bestLogP:
+			bestLogP = logP
+			ties = append(ties[:0], lbl)
+		case logP == bestLogP:
+			ties = append(ties, lbl)
+		}
+	}
+
+	if len(ties) == 0 {
+		return -1
+	}
+	switch {
+	case strategy == TieHighestPrior && gc.LogPrior != nil:
+		best := ties[0]
+		for _, lbl := range ties[1:] {
+			if gc.LogPrior[lbl] > gc.LogPrior[best] {
+				best = lbl
+			}
+		}
+		return best
+	case strategy == TieRandom && rng != nil:
+		return ties[rng.Intn(len(ties))]
+	}
+	return ties[0]
+}
+
+// Implementation of Classifier.Classify. Among labels with equal posteriors,
+// the lowest one is returned.
 func (gc *GaussianClassifier) Classify(x []float64) int {
 	bestLogP := 0.
 	bestLabel := -1
@@ -58,6 +266,93 @@ func (gc *GaussianClassifier) Classify(x []float64) int {
 	return bestLabel
 }
 
+// Implementation of ScoredClassifier.Scores. The scores are the logarithm of
+// the posterior probabilities.
+func (gc *GaussianClassifier) Scores(x []float64, scores []float64) {
+	for lbl := range gc.LogCoefs {
+		scores[lbl] = gc.LogPosterior(lbl, x)
+	}
+}
+
+// Implementation of ProbClassifier.ClassifyProb
+func (gc *GaussianClassifier) ClassifyProb(x []float64) []float64 {
+	probs := gc.ClassifyProbLog(x)
+	for i := range probs {
+		probs[i] = math.Exp(probs[i])
+	}
+	return probs
+}
+
+/*
+	ClassifyProbLog returns the logarithm of the normalized posterior
+	probabilities of all labels for the feature x. It is more precise than
+	taking the logarithm of the result of ClassifyProb.
+*/
+func (gc *GaussianClassifier) ClassifyProbLog(x []float64) []float64 {
+	logPs := make([]float64, gc.LabelCount())
+	gc.Scores(x, logPs)
+	normalizeLog(logPs)
+	return logPs
+}
+
+/*
+	ClassifyMasked classifies x ignoring the dimensions k with mask[k] set.
+
+	For a diagonal model, masked dimensions are marginalized out, i.e. they are
+	skipped in the likelyhood, including its coefficient. Feature weights apply
+	as in LogLikelyhood. For a full model, the mean of each label is
+	substituted for masked dimensions.
+
+	-1 is returned if len(mask) is not gc.Dim().
+*/
+func (gc *GaussianClassifier) ClassifyMasked(x []float64, mask []bool) int {
+	dim := gc.Dim()
+	if len(mask) != dim {
+		return -1
+	}
+
+	bestLogP := 0.
+	bestLabel := -1
+
+	xm := make([]float64, dim)
+	for lbl, mean := range gc.Means {
+		var logP float64
+		if gc.Diagonal {
+			prec := gc.Precs[lbl]
+			logP = 0.
+			for k := range mean {
+				if mask[k] {
+					continue
+				}
+				vk := x[k] - mean[k]
+				if gc.FeatureWeights != nil {
+					vk *= math.Sqrt(gc.FeatureWeights[k])
+				}
+				/* log(1/sqrt(2*Pi*variance)) = 1/2*log(-prec/Pi) */
+				logP += vk*vk*prec[k*dim+k] + 0.5*math.Log(-prec[k*dim+k]/math.Pi)
+			}
+		} else {
+			for k := range xm {
+				if mask[k] {
+					xm[k] = mean[k]
+				} else {
+					xm[k] = x[k]
+				}
+			}
+			logP = gc.LogLikelyhood(lbl, xm)
+		}
+		if gc.LogPrior != nil {
+			logP += gc.LogPrior[lbl]
+		}
+
+		if bestLabel < 0 || logP > bestLogP {
+			bestLabel, bestLogP = lbl, logP
+		}
+	}
+
+	return bestLabel
+}
+
 /*
 	LogLikelyhood returns the logarithm of the likelyhood of the feature x on a
 	specified label.
@@ -70,13 +365,38 @@ func (gc *GaussianClassifier) LogLikelyhood(label int, x []float64) float64 {
 
 	dim := len(mean)
 
-	/* logP += (x -  mu)' * Sigma * (x - mu) */
+	if gc.FeatureWeights != nil {
+		/* scaling x - mu by sqrt(w) scales the entry (k, l) by sqrt(w_k*w_l) */
+		xs := make([]float64, dim)
+		for k := range xs {
+			xs[k] = mean[k] + (x[k]-mean[k])*math.Sqrt(gc.FeatureWeights[k])
+		}
+		x = xs
+	}
+
+	if gc.Diagonal {
+		/* grouped as in the general loop below, which gives identical results */
+		for k := range mean {
+			vk := x[k] - mean[k]
+			logP += vk * (vk * prec[k*dim+k])
+		}
+		return logP
+	}
+
+	/*
+		logP += (x -  mu)' * Sigma * (x - mu)
+
+		Sigma is symmetric, so each off-diagonal term is computed once and
+		doubled.
+	*/
 	for k := range mean {
 		vk := x[k] - mean[k]
-		for l := range mean {
-			vl := x[l] - mean[l]
-			logP += vk * vl * prec[k*dim+l]
+		row := prec[k*dim : (k+1)*dim]
+		off := 0.
+		for l := k + 1; l < dim; l++ {
+			off += (x[l] - mean[l]) * row[l]
 		}
+		logP += vk * (vk*row[k] + 2.*off)
 	}
 
 	return logP
@@ -93,16 +413,346 @@ func (gc *GaussianClassifier) LogPosterior(label int, x []float64) float64 {
 	return gc.LogLikelyhood(label, x) + gc.LogPrior[label]
 }
 
+/*
+	PriorInfluence returns, for every label, LogPosterior minus LogLikelyhood of
+	x, i.e. how much the prior shifts the score of the label. All zeros are
+	returned if no prior is set.
+*/
+func (gc *GaussianClassifier) PriorInfluence(x []float64) []float64 {
+	infl := make([]float64, gc.LabelCount())
+	for lbl := range infl {
+		infl[lbl] = gc.LogPosterior(lbl, x) - gc.LogLikelyhood(lbl, x)
+	}
+	return infl
+}
+
+/*
+	Explain returns the additive contribution of every dimension of x to
+	LogLikelyhood(label, x). The contributions sum to LogLikelyhood minus the
+	log-coefficient of the label. For a full covariance model, each cross term
+	is attributed half-and-half to its two dimensions.
+*/
+func (gc *GaussianClassifier) Explain(label int, x []float64) []float64 {
+	mean := gc.Means[label]
+	prec := gc.Precs[label]
+	dim := len(mean)
+
+	diff := make([]float64, dim)
+	for k := range diff {
+		diff[k] = x[k] - mean[k]
+		if gc.FeatureWeights != nil {
+			diff[k] *= math.Sqrt(gc.FeatureWeights[k])
+		}
+	}
+
+	contribs := make([]float64, dim)
+	for k := range contribs {
+		if gc.Diagonal {
+			contribs[k] = diff[k] * diff[k] * prec[k*dim+k]
+			continue
+		}
+		/* the term (k, l) and its symmetric (l, k) give half to each of k, l */
+		sum := 0.
+		for l := range diff {
+			sum += prec[k*dim+l] * diff[l]
+		}
+		contribs[k] = diff[k] * sum
+	}
+	return contribs
+}
+
+/*
+	OffDiagonalMass returns the sum of the absolute off-diagonal entries of the
+	covariance matrix of a label divided by the sum of its absolute diagonal
+	entries. A value near zero means a diagonal model loses little. It is 0 for
+	a diagonal model and NaN if the covariance can not be reconstructed.
+*/
+func (gc *GaussianClassifier) OffDiagonalMass(label int) float64 {
+	if gc.Diagonal {
+		return 0
+	}
+	sigma, err := gc.covariance(label)
+	if err != nil {
+		return math.NaN()
+	}
+
+	dim := len(gc.Means[label])
+	diag, off := 0., 0.
+	for k := 0; k < dim; k++ {
+		for l := 0; l < dim; l++ {
+			if k == l {
+				diag += math.Abs(sigma[k*dim+l])
+			} else {
+				off += math.Abs(sigma[k*dim+l])
+			}
+		}
+	}
+	return off / diag
+}
+
+/*
+	covariance reconstructs the covariance matrix Sigma of a label from its
+	precision.
+*/
+func (gc *GaussianClassifier) covariance(label int) ([]float64, error) {
+	dim := len(gc.Means[label])
+	prec := make([]float64, dim*dim)
+	for i, p := range gc.Precs[label] {
+		prec[i] = -2. * p
+	}
+
+	inv, err := matrix.MakeDenseMatrix(prec, dim, dim).Inverse()
+	if err != nil {
+		return nil, err
+	}
+	return inv.Array(), nil
+}
+
+/*
+	Validate checks that the precision matrix of every label is symmetric, has
+	negative diagonal entries (because of the -1/2 scaling), and that the
+	reconstructed covariance matrix is positive definite. A nil error is
+	returned if all labels pass, otherwise the error describes every failing
+	label.
+*/
+func (gc *GaussianClassifier) Validate() error {
+	var msgs []string
+	for lbl := range gc.Means {
+		if err := gc.validateLabel(lbl); err != nil {
+			msgs = append(msgs, fmt.Sprintf("label %d: %v", lbl, err))
+		}
+	}
+	if len(msgs) > 0 {
+		return errors.New(strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+func (gc *GaussianClassifier) validateLabel(label int) error {
+	dim := len(gc.Means[label])
+	prec := gc.Precs[label]
+	if len(prec) != dim*dim {
+		return fmt.Errorf("precision has %d entries, expected %d", len(prec), dim*dim)
+	}
+
+	for k := 0; k < dim; k++ {
+		if !(prec[k*dim+k] < 0) {
+			return fmt.Errorf("precision diagonal %d is %v, expected negative", k, prec[k*dim+k])
+		}
+		for l := 0; l < k; l++ {
+			a, b := prec[k*dim+l], prec[l*dim+k]
+			if math.Abs(a-b) > 1e-9*math.Max(1, math.Max(math.Abs(a), math.Abs(b))) {
+				return fmt.Errorf("precision is asymmetric at (%d, %d): %v != %v", k, l, a, b)
+			}
+		}
+	}
+
+	sigma, err := gc.covariance(label)
+	if err != nil {
+		return fmt.Errorf("covariance can not be reconstructed: %v", err)
+	}
+	if _, ok := cholesky(sigma, dim); !ok {
+		return errors.New("covariance is not positive definite")
+	}
+	return nil
+}
+
+// checkFinite returns an error naming the first value of v that is NaN or
+// infinite.
+func checkFinite(v []float64) error {
+	for i, x := range v {
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return fmt.Errorf("entry %d is %v", i, x)
+		}
+	}
+	return nil
+}
+
+/*
+	NewValidatedGaussianClassifier returns a *GaussianClassifier with the means,
+	the precisions (the inverse matrices of Sigma times -1/2) and the
+	log-coefficients, after checking that the label counts agree, every
+	precision is a square matrix matching the dimension of the means, all values
+	are finite and every label passes the checks of Validate. The error
+	describes the first problem found. The slices are not copied.
+*/
+func NewValidatedGaussianClassifier(means, precs [][]float64, logCoefs []float64) (*GaussianClassifier, error) {
+	if len(means) == 0 {
+		return nil, errors.New("no labels")
+	}
+	if len(precs) != len(means) || len(logCoefs) != len(means) {
+		return nil, fmt.Errorf("%d means, %d precisions and %d log-coefficients, expected the same numbers", len(means), len(precs), len(logCoefs))
+	}
+
+	dim := len(means[0])
+	for lbl := range means {
+		if len(means[lbl]) != dim {
+			return nil, fmt.Errorf("label %d: mean has dimension %d, expected %d", lbl, len(means[lbl]), dim)
+		}
+		if len(precs[lbl]) != dim*dim {
+			return nil, fmt.Errorf("label %d: precision has %d entries, expected %d", lbl, len(precs[lbl]), dim*dim)
+		}
+		if err := checkFinite(means[lbl]); err != nil {
+			return nil, fmt.Errorf("label %d: mean %v", lbl, err)
+		}
+		if err := checkFinite(precs[lbl]); err != nil {
+			return nil, fmt.Errorf("label %d: precision %v", lbl, err)
+		}
+		if math.IsNaN(logCoefs[lbl]) || math.IsInf(logCoefs[lbl], 0) {
+			return nil, fmt.Errorf("label %d: log-coefficient is %v", lbl, logCoefs[lbl])
+		}
+	}
+
+	gc := &GaussianClassifier{Means: means, Precs: precs, LogCoefs: logCoefs}
+	for lbl := range means {
+		if err := gc.validateLabel(lbl); err != nil {
+			return nil, fmt.Errorf("label %d: %v", lbl, err)
+		}
+	}
+	return gc, nil
+}
+
 /*
 	The trainer for a Gaussian classifier
 */
 type GaussianTrainer struct {
+	// if true, a label with a single feature gets an isotropic covariance
+	// matrix, the global variance (the mean variance of all dimensions over all
+	// features) times the identity, instead of failing training
+	SingleSampleFallback bool
+	// added to the diagonal of every covariance matrix before inversion, a
+	// small ridge (e.g. 1e-6) that keeps near-singular matrices invertible
+	DiagonalEpsilon float64
+}
+
+/*
+	featureMean returns the mean of the features of a label. x is a buffer of
+	dimension lfs.Dim().
+*/
+func featureMean(lfs LabeledFeatureSet, label int, x []float64) []float64 {
+	mean := make([]float64, len(x))
+
+	cnt := lfs.FeatureCount(label)
+	for i := 0; i < cnt; i++ {
+		lfs.FetchFeature(label, i, x)
+		for k := range x {
+			mean[k] += x[k]
+		}
+	}
+
+	for k := range mean {
+		mean[k] /= float64(cnt)
+	}
+
+	return mean
+}
+
+/*
+	addScatter adds the scatter matrix of the features of a label around mean to
+	the right-top part (including the diagonal) of sigma. x is a buffer of
+	dimension lfs.Dim().
+*/
+func addScatter(lfs LabeledFeatureSet, label int, mean, x, sigma []float64) {
+	dim := len(mean)
+	cnt := lfs.FeatureCount(label)
+	for i := 0; i < cnt; i++ {
+		lfs.FetchFeature(label, i, x)
+		for k := 0; k < dim; k++ {
+			for l := k; l < dim; l++ {
+				sigma[k*dim+l] += (x[k] - mean[k]) * (x[l] - mean[l])
+			}
+		}
+	}
+}
+
+// symmetrize copies the left-bottom part of sigma from the right-top part.
+func symmetrize(sigma []float64, dim int) {
+	for k := 0; k < dim; k++ {
+		for l := 0; l < k; l++ {
+			sigma[k*dim+l] = sigma[l*dim+k]
+		}
+	}
+}
+
+/*
+	shrinkToDiagonal scales the off-diagonal entries of sigma by 1 - lambda,
+	i.e. sigma becomes (1 - lambda) * sigma + lambda * diag(sigma).
+*/
+func shrinkToDiagonal(sigma []float64, dim int, lambda float64) {
+	for k := 0; k < dim; k++ {
+		for l := 0; l < dim; l++ {
+			if k != l {
+				sigma[k*dim+l] *= 1 - lambda
+			}
+		}
+	}
+}
+
+/*
+	gaussianPrec computes the inverse of a covariance matrix sigma times -1/2,
+	and the logarithm coefficent of the Gaussian distribution.
+
+	A covariance matrix is positive definite, so it is inverted through its
+	Cholesky factorization, which is faster and more stable than a general
+	inversion. The general inversion is used only if the factorization fails,
+	e.g. for a nearly collinear covariance whose rounding errors make it
+	slightly indefinite; the resulting model is then reported by Validate. An
+	error is returned if sigma is singular.
+*/
+func gaussianPrec(sigma []float64, dim int) (prec []float64, logCoef float64, err error) {
+	/*
+		log(det(Sigma)) is computed from the Cholesky factor, or from the LU
+		factorization in the fallback, since det(Sigma) itself easily overflows
+		or underflows in high dimensions. The fallback takes log|det(Sigma)|,
+		which keeps the coefficient finite for an indefinite matrix.
+	*/
+	var logDet float64
+	if L, ok := cholesky(sigma, dim); ok {
+		prec = choleskyInverse(L, dim)
+		logDet = choleskyLogDet(L, dim)
+	} else {
+		var ok bool
+		if logDet, ok = luLogAbsDet(sigma, dim); !ok {
+			return nil, 0, errors.New("singular matrix")
+		}
+		inv, err := matrix.MakeDenseMatrix(sigma, dim, dim).Inverse()
+		if err != nil {
+			return nil, 0, err
+		}
+		prec = inv.Array()
+	}
+
+	for i := range prec {
+		prec[i] *= -0.5
+	}
+
+	return prec, -0.5 * (math.Log(2.*math.Pi)*float64(dim) + logDet), nil
 }
 
 /*
-	GaussianTrain trains a *GaussianClassifier from a LabeledFeatureSet.
+	GaussianTrain trains a *GaussianClassifier from a LabeledFeatureSet. nil is
+	returned if training fails; use GaussianTrainer.TrainGaussian for the
+	reason.
 */
 func GaussianTrain(lfs LabeledFeatureSet) *GaussianClassifier {
+	gc, _ := (&GaussianTrainer{}).TrainGaussian(lfs)
+	return gc
+}
+
+/*
+	TrainGaussian trains a *GaussianClassifier from a LabeledFeatureSet. The
+	returned error identifies the label failing training: one without features,
+	one with a single feature (unless SingleSampleFallback is set), or one with a
+	singular covariance matrix.
+
+	If lfs is a SoftLabeledFeatureSet, every feature contributes to every label
+	weighted by its soft label probability of the label.
+*/
+func (gt *GaussianTrainer) TrainGaussian(lfs LabeledFeatureSet) (*GaussianClassifier, error) {
+	if sfs, ok := lfs.(SoftLabeledFeatureSet); ok {
+		return gt.trainSoft(sfs)
+	}
+
 	lblCnt := lfs.LabelCount()
 	dim := lfs.Dim()
 	clsfr := &GaussianClassifier{
@@ -113,65 +763,71 @@ func GaussianTrain(lfs LabeledFeatureSet) *GaussianClassifier {
 
 	x := make([]float64, dim)
 
+	globalVar := -1.
 	sigma := make([]float64, dim*dim)
 	for lbl := range clsfr.Means {
-		mean := make([]float64, dim)
-
 		cnt := lfs.FeatureCount(lbl)
-
-		for i := 0; i < cnt; i++ {
-			lfs.FetchFeature(lbl, i, x)
-			for k := range x {
-				mean[k] += x[k]
-			}
-		}
-
-		for k := range mean {
-			mean[k] /= float64(cnt)
+		switch {
+		case cnt == 0:
+			return nil, fmt.Errorf("label %d has no features", lbl)
+		case cnt == 1 && !gt.SingleSampleFallback:
+			return nil, fmt.Errorf("label %d has only one feature, its covariance matrix is zero", lbl)
 		}
+		mean := featureMean(lfs, lbl, x)
 
 		for i := range sigma {
 			sigma[i] = 0.
 		}
-		for i := 0; i < cnt; i++ {
-			lfs.FetchFeature(lbl, i, x)
-			for k := 0; k < dim; k++ {
-				for l := k; l < dim; l++ {
-					sigma[k*dim+l] += (x[k] - mean[k]) * (x[l] - mean[l])
-				}
-			}
-		}
 		if cnt > 1 {
+			addScatter(lfs, lbl, mean, x, sigma)
 			for i := range sigma {
 				sigma[i] /= float64(cnt - 1)
 			}
+			symmetrize(sigma, dim)
+		} else {
+			if globalVar < 0 {
+				globalVar = globalVariance(lfs)
+			}
+			for k := 0; k < dim; k++ {
+				sigma[k*dim+k] = globalVar
+			}
 		}
-		// copy the left-bottom part from right-top part
+
 		for k := 0; k < dim; k++ {
-			for l := 0; l < k; l++ {
-				sigma[k*dim+l] = sigma[l*dim+k]
-			}
+			sigma[k*dim+k] += gt.DiagonalEpsilon
 		}
 
-		mat := matrix.MakeDenseMatrix(sigma, dim, dim)
-		inv, err := mat.Inverse()
+		prec, logCoef, err := gaussianPrec(sigma, dim)
 		if err != nil {
-			return nil
+			return nil, fmt.Errorf("label %d: covariance matrix is singular: %v", lbl, err)
 		}
 
-		inv.Scale(-0.5)
-
-		det := mat.Det()
-
 		clsfr.Means[lbl] = mean
-		clsfr.Precs[lbl] = inv.Array()
-		clsfr.LogCoefs[lbl] = -0.5 * (math.Log(2.*math.Pi)*float64(dim) + math.Log(det))
+		clsfr.Precs[lbl] = prec
+		clsfr.LogCoefs[lbl] = logCoef
 	}
 
-	return clsfr
+	return clsfr, nil
+}
+
+// globalVariance returns the mean variance of all dimensions over all
+// features in lfs.
+func globalVariance(lfs LabeledFeatureSet) float64 {
+	st := DatasetStats(lfs)
+	v := 0.
+	for _, std := range st.Std {
+		v += std * std
+	}
+	if st.Dim > 0 {
+		v /= float64(st.Dim)
+	}
+	return v
 }
 
-// Implementation of Trainer.Train
+// Implementation of Trainer.Train. nil is returned if training fails.
 func (gt *GaussianTrainer) Train(lfs LabeledFeatureSet) Classifier {
-	return GaussianTrain(lfs)
+	if gc, err := gt.TrainGaussian(lfs); err == nil {
+		return gc
+	}
+	return nil
 }
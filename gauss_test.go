@@ -0,0 +1,387 @@
+package pr
+
+import (
+	"github.com/skelterjohn/go.matrix"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestGaussianPrecIndefiniteFallback(t *testing.T) {
+	/* invertible, but with eigenvalues 3 and -1, so Cholesky fails */
+	prec, logCoef, err := gaussianPrec([]float64{1, 2, 2, 1}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	/* inv = [[-1, 2], [2, -1]] / 3, |det| = 3 */
+	for i, want := range []float64{1. / 6, -1. / 3, -1. / 3, 1. / 6} {
+		if math.Abs(prec[i]-want) > 1e-12 {
+			t.Errorf("precision is %v, expected -1/2 times the inverse", prec)
+			break
+		}
+	}
+	if want := -math.Log(2*math.Pi) - 0.5*math.Log(3); math.Abs(logCoef-want) > 1e-12 {
+		t.Errorf("log-coefficient is %v, expected %v", logCoef, want)
+	}
+
+	gc := &GaussianClassifier{Means: [][]float64{{0, 0}}, Precs: [][]float64{prec}, LogCoefs: []float64{logCoef}}
+	if err := gc.Validate(); err == nil {
+		t.Error("expected Validate to report the indefinite covariance")
+	}
+
+	if _, _, err := gaussianPrec([]float64{1, 1, 1, 1}, 2); err == nil {
+		t.Error("expected an error for a singular matrix")
+	}
+}
+
+func gaussianSet20() *SliceFeatureSet {
+	means := make([][]float64, 3)
+	for lbl := range means {
+		means[lbl] = make([]float64, 20)
+		for k := range means[lbl] {
+			means[lbl][k] = float64(lbl + k%3)
+		}
+	}
+	return randomSet(1, 500, means...)
+}
+
+func BenchmarkGaussianTrain20(b *testing.B) {
+	sfs := gaussianSet20()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := (&GaussianTrainer{}).TrainGaussian(sfs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkGaussianPrec20 compares the Cholesky path of gaussianPrec with the
+// general Inverse and Det it replaced, on 20-dimensional covariances.
+func BenchmarkGaussianPrec20(b *testing.B) {
+	gc, err := (&GaussianTrainer{}).TrainGaussian(gaussianSet20())
+	if err != nil {
+		b.Fatal(err)
+	}
+	sigmas := make([][]float64, gc.LabelCount())
+	for lbl := range sigmas {
+		if sigmas[lbl], err = gc.covariance(lbl); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Run("cholesky", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, sigma := range sigmas {
+				if _, _, err := gaussianPrec(sigma, 20); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("inverse", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, sigma := range sigmas {
+				mat := matrix.MakeDenseMatrix(sigma, 20, 20)
+				if _, err := mat.Inverse(); err != nil {
+					b.Fatal(err)
+				}
+				math.Log(mat.Det())
+			}
+		}
+	})
+}
+
+func TestGaussianTrainHighDimLogCoefsFinite(t *testing.T) {
+	/* det(Sigma) is about 1e-800, which underflows a float64 */
+	const dim, n = 200, 300
+	rng := rand.New(rand.NewSource(1))
+	sfs := &SliceFeatureSet{FeatureDim: dim, Features: make([][][]float64, 2)}
+	for lbl := range sfs.Features {
+		for i := 0; i < n; i++ {
+			x := make([]float64, dim)
+			for k := range x {
+				x[k] = float64(lbl) + 0.01*rng.NormFloat64()
+			}
+			sfs.Features[lbl] = append(sfs.Features[lbl], x)
+		}
+	}
+
+	gc, err := (&GaussianTrainer{}).TrainGaussian(sfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for lbl, logCoef := range gc.LogCoefs {
+		if math.IsNaN(logCoef) || math.IsInf(logCoef, 0) {
+			t.Errorf("LogCoefs[%d] = %v, expected finite", lbl, logCoef)
+		}
+	}
+	if got := gc.Classify(sfs.Features[1][0]); got != 1 {
+		t.Errorf("Classify = %d, expected 1", got)
+	}
+}
+
+func TestClassifyMaskedFeatureWeights(t *testing.T) {
+	sfs := randomSet(3, 100, []float64{0, 0, 0}, []float64{1, 4, 1}, []float64{3, -2, 2})
+	gc := DiagonalGaussianTrain(sfs)
+	if err := gc.SetFeatureWeights([]float64{1, 1e-4, 0.5}); err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewSource(4))
+	mask := make([]bool, 3)
+	for i := 0; i < 500; i++ {
+		x := []float64{rng.NormFloat64() * 3, rng.NormFloat64() * 4, rng.NormFloat64() * 3}
+		if got, want := gc.ClassifyMasked(x, mask), gc.Classify(x); got != want {
+			t.Errorf("x = %v: ClassifyMasked gives %d, Classify gives %d", x, got, want)
+		}
+	}
+}
+
+func TestClassifyTieBreak(t *testing.T) {
+	/* at x = 0 the labels 0, 1 and 2 have exactly equal posteriors, label 1
+	   with the highest prior; label 3 is far away */
+	gc := &GaussianClassifier{
+		Means:    [][]float64{{-1}, {1}, {1}, {5}},
+		Precs:    [][]float64{{-0.5}, {-0.5}, {-0.5}, {-0.5}},
+		LogCoefs: []float64{-1, -1.5, -1, -1},
+		LogPrior: []float64{-1, -0.5, -1, -1},
+	}
+	x := []float64{0}
+
+	if lbl := gc.ClassifyTieBreak(x, TieLowestLabel, nil); lbl != 0 {
+		t.Errorf("TieLowestLabel gives %d, expected 0", lbl)
+	}
+	if lbl := gc.ClassifyTieBreak(x, TieHighestPrior, nil); lbl != 1 {
+		t.Errorf("TieHighestPrior gives %d, expected 1", lbl)
+	}
+	if lbl := gc.ClassifyTieBreak(x, TieRandom, nil); lbl != 0 {
+		t.Errorf("TieRandom without rng gives %d, expected 0", lbl)
+	}
+
+	draw := func(seed int64) []int {
+		rng := rand.New(rand.NewSource(seed))
+		lbls := make([]int, 300)
+		for i := range lbls {
+			lbls[i] = gc.ClassifyTieBreak(x, TieRandom, rng)
+		}
+		return lbls
+	}
+	lbls := draw(1)
+	counts := make([]int, gc.LabelCount())
+	for _, lbl := range lbls {
+		counts[lbl]++
+	}
+	if counts[0] == 0 || counts[1] == 0 || counts[2] == 0 || counts[3] != 0 {
+		t.Errorf("TieRandom picks labels %v times, expected only and all of the tied labels 0, 1 and 2", counts)
+	}
+	for i, lbl := range draw(1) {
+		if lbl != lbls[i] {
+			t.Fatalf("TieRandom differs at draw %d for the same seed: %d != %d", i, lbl, lbls[i])
+		}
+	}
+}
+
+func TestGaussianTrainSingleSampleFallback(t *testing.T) {
+	sfs := randomSet(5, 50, []float64{0, 0}, []float64{4, 4})
+	single := []float64{-3, 6}
+	sfs.Features = append(sfs.Features, [][]float64{single})
+
+	if _, err := (&GaussianTrainer{}).TrainGaussian(sfs); err == nil {
+		t.Error("expected an error for a single-sample label without the fallback")
+	}
+
+	gc, err := (&GaussianTrainer{SingleSampleFallback: true}).TrainGaussian(sfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range gc.Means[2] {
+		if v != single[k] {
+			t.Errorf("mean of the single-sample label is %v, expected %v", gc.Means[2], single)
+			break
+		}
+	}
+	want := -0.5 / globalVariance(sfs)
+	prec := gc.Precs[2]
+	if math.Abs(prec[0]-want) > 1e-12*math.Abs(want) || prec[3] != prec[0] || prec[1] != 0 || prec[2] != 0 {
+		t.Errorf("precision of the single-sample label is %v, expected %g times the identity", prec, want)
+	}
+	if math.IsNaN(gc.LogCoefs[2]) || math.IsInf(gc.LogCoefs[2], 0) {
+		t.Errorf("log-coefficient of the single-sample label is %v", gc.LogCoefs[2])
+	}
+	if lbl := gc.Classify(single); lbl != 2 {
+		t.Errorf("the single sample is classified as %d, expected 2", lbl)
+	}
+}
+
+func TestGaussianTrainDiagonalEpsilon(t *testing.T) {
+	/* the second dimension duplicates the first, so both covariance matrices
+	   are exactly [[4, 4], [4, 4]], which is singular */
+	sfs := &SliceFeatureSet{
+		FeatureDim: 2,
+		Features: [][][]float64{
+			{{-2, -2}, {0, 0}, {2, 2}},
+			{{8, 8}, {10, 10}, {12, 12}},
+		},
+	}
+	if _, err := (&GaussianTrainer{}).TrainGaussian(sfs); err == nil {
+		t.Error("expected an error for a singular covariance matrix without DiagonalEpsilon")
+	}
+
+	const eps = 1e-4
+	gc, err := (&GaussianTrainer{DiagonalEpsilon: eps}).TrainGaussian(sfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gc.Validate(); err != nil {
+		t.Errorf("invalid model: %v", err)
+	}
+	for lbl := range gc.Means {
+		if math.IsNaN(gc.LogCoefs[lbl]) || math.IsInf(gc.LogCoefs[lbl], 0) {
+			t.Errorf("label %d: log-coefficient is %v", lbl, gc.LogCoefs[lbl])
+		}
+		sigma, err := gc.covariance(lbl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, want := range []float64{4 + eps, 4, 4, 4 + eps} {
+			if math.Abs(sigma[i]-want) > 1e-7 {
+				t.Errorf("label %d: covariance is %v, expected [[4+eps, 4], [4, 4+eps]]", lbl, sigma)
+				break
+			}
+		}
+	}
+	if lbl := gc.Classify([]float64{1, 1}); lbl != 0 {
+		t.Errorf("(1, 1) is classified as %d, expected 0", lbl)
+	}
+	if lbl := gc.Classify([]float64{9, 9}); lbl != 1 {
+		t.Errorf("(9, 9) is classified as %d, expected 1", lbl)
+	}
+}
+
+func TestLogLikelyhoodDiagonalFastPath(t *testing.T) {
+	diag := DiagonalGaussianTrain(randomSet(6, 100, []float64{0, 0, 0, 0}, []float64{1, 2, 3, 4}))
+	full := diag.Clone()
+	full.Diagonal = false
+
+	rng := rand.New(rand.NewSource(7))
+	x := make([]float64, 4)
+	for _, weights := range [][]float64{nil, {1, 0.5, 1e-4, 2}} {
+		if err := diag.SetFeatureWeights(weights); err != nil {
+			t.Fatal(err)
+		}
+		full.FeatureWeights = weights
+		for i := 0; i < 200; i++ {
+			for k := range x {
+				x[k] = rng.NormFloat64() * 3
+			}
+			for lbl := range diag.Means {
+				if d, f := diag.LogLikelyhood(lbl, x), full.LogLikelyhood(lbl, x); d != f {
+					t.Fatalf("weights %v, x = %v, label %d: diagonal path gives %v, general path %v", weights, x, lbl, d, f)
+				}
+			}
+			if d, f := diag.Classify(x), full.Classify(x); d != f {
+				t.Fatalf("weights %v, x = %v: diagonal path classifies as %d, general path as %d", weights, x, d, f)
+			}
+		}
+	}
+}
+
+func TestCloneIndependent(t *testing.T) {
+	newModel := func() *GaussianClassifier {
+		return &GaussianClassifier{
+			Means:          [][]float64{{0, 1}, {2, 3}},
+			Precs:          [][]float64{{-0.5, 0.1, 0.1, -0.5}, {-1, 0, 0, -0.25}},
+			LogCoefs:       []float64{-1.9, -1.8},
+			LogPrior:       []float64{-0.5, -0.9},
+			Counts:         []int{10, 20},
+			FeatureWeights: []float64{1, 0.5},
+			Shrinkages:     []float64{0.1, 0.2},
+		}
+	}
+	gc := newModel()
+	c := gc.Clone()
+	if !reflect.DeepEqual(c, gc) {
+		t.Fatalf("clone %+v differs from %+v", c, gc)
+	}
+
+	c.Means[0][1] = 7
+	c.Precs[1][3] = -7
+	c.LogCoefs[0] = 7
+	c.LogPrior[1] = 7
+	c.Counts[0] = 7
+	c.FeatureWeights[1] = 7
+	c.Shrinkages[0] = 7
+	c.Means = append(c.Means, []float64{4, 5})
+	if want := newModel(); !reflect.DeepEqual(gc, want) {
+		t.Errorf("mutating the clone changed the original to %+v, expected %+v", gc, want)
+	}
+}
+
+// naiveLogLikelyhood is the plain double loop over the precision matrix,
+// the reference for LogLikelyhood.
+func naiveLogLikelyhood(gc *GaussianClassifier, label int, x []float64) float64 {
+	mean, prec := gc.Means[label], gc.Precs[label]
+	dim := len(mean)
+	logP := gc.LogCoefs[label]
+	for k := range mean {
+		for l := range mean {
+			logP += (x[k] - mean[k]) * (x[l] - mean[l]) * prec[k*dim+l]
+		}
+	}
+	return logP
+}
+
+func trainedGaussian(tb testing.TB, dim int) *GaussianClassifier {
+	means := make([][]float64, 3)
+	for lbl := range means {
+		means[lbl] = make([]float64, dim)
+		for k := range means[lbl] {
+			means[lbl][k] = float64(lbl + k%3)
+		}
+	}
+	gc, err := (&GaussianTrainer{}).TrainGaussian(randomSet(1, 5*dim+20, means...))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return gc
+}
+
+func TestLogLikelyhoodMatchesNaive(t *testing.T) {
+	rng := rand.New(rand.NewSource(8))
+	for _, dim := range []int{1, 2, 5, 20} {
+		gc := trainedGaussian(t, dim)
+		x := make([]float64, dim)
+		for i := 0; i < 100; i++ {
+			for k := range x {
+				x[k] = rng.NormFloat64() * 3
+			}
+			for lbl := range gc.Means {
+				/* the summation orders differ, so the results agree up to
+				   rounding only */
+				got, want := gc.LogLikelyhood(lbl, x), naiveLogLikelyhood(gc, lbl, x)
+				if math.Abs(got-want) > 1e-12*math.Max(1, math.Abs(want)) {
+					t.Fatalf("dim %d, label %d: LogLikelyhood gives %v, the double loop %v", dim, lbl, got, want)
+				}
+			}
+		}
+	}
+}
+
+func benchmarkLogLikelyhood(b *testing.B, dim int) {
+	gc := trainedGaussian(b, dim)
+	x := make([]float64, dim)
+	for k := range x {
+		x[k] = float64(k % 5)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		gc.LogLikelyhood(i%gc.LabelCount(), x)
+	}
+}
+
+func BenchmarkLogLikelyhood2(b *testing.B)   { benchmarkLogLikelyhood(b, 2) }
+func BenchmarkLogLikelyhood20(b *testing.B)  { benchmarkLogLikelyhood(b, 20) }
+func BenchmarkLogLikelyhood100(b *testing.B) { benchmarkLogLikelyhood(b, 100) }
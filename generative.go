@@ -0,0 +1,77 @@
+package pr
+
+import (
+	"math"
+)
+
+/*
+	A *GenerativeClassifier classifies a feature by the largest log-posterior,
+	the log-density of a label plus its log-prior. Any DensityModel, e.g. the
+	Gaussians given by GaussianClassifier.Densities, can be used for a label.
+*/
+type GenerativeClassifier struct {
+	// the density of every label
+	Densities []DensityModel
+	// if non-nil, the logarithm of prior priorities
+	LogPrior []float64
+}
+
+/*
+	LogPosterior returns the logarithm of the posterior probability, up to a
+	constant, of a feature on a specified label.
+*/
+func (gc *GenerativeClassifier) LogPosterior(label int, x []float64) float64 {
+	logP := gc.Densities[label].LogDensity(x)
+	if gc.LogPrior != nil {
+		logP += gc.LogPrior[label]
+	}
+	return logP
+}
+
+// Implementation of Classifier.Classify
+func (gc *GenerativeClassifier) Classify(x []float64) int {
+	bestLabel, bestLogP := -1, 0.
+	for lbl := range gc.Densities {
+		if logP := gc.LogPosterior(lbl, x); bestLabel < 0 || logP > bestLogP {
+			bestLabel, bestLogP = lbl, logP
+		}
+	}
+	return bestLabel
+}
+
+// Implementation of ProbClassifier.ClassifyProb
+func (gc *GenerativeClassifier) ClassifyProb(x []float64) []float64 {
+	probs := make([]float64, len(gc.Densities))
+	for lbl := range probs {
+		probs[lbl] = gc.LogPosterior(lbl, x)
+	}
+	normalizeLog(probs)
+	for i := range probs {
+		probs[i] = math.Exp(probs[i])
+	}
+	return probs
+}
+
+// gaussianDensity is the DensityModel of a label of a GaussianClassifier.
+type gaussianDensity struct {
+	gc    *GaussianClassifier
+	label int
+}
+
+// Implementation of DensityModel.LogDensity
+func (gd gaussianDensity) LogDensity(x []float64) float64 {
+	return gd.gc.LogLikelyhood(gd.label, x)
+}
+
+/*
+	Densities returns the Gaussian of every label as a DensityModel, referring
+	to gc. &GenerativeClassifier{gc.Densities(), gc.LogPrior} classifies as gc
+	does.
+*/
+func (gc *GaussianClassifier) Densities() []DensityModel {
+	densities := make([]DensityModel, gc.LabelCount())
+	for lbl := range densities {
+		densities[lbl] = gaussianDensity{gc, lbl}
+	}
+	return densities
+}
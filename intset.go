@@ -0,0 +1,88 @@
+package pr
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+	IntFeatureSet is a LabeledFeatureSet of integer-valued features, e.g. pixel
+	intensities, stored in memory as int32s, half the size of float64s, and
+	converted to float64 by FetchFeature.
+*/
+type IntFeatureSet struct {
+	// the dimension of features
+	FeatureDim int
+	// Features[label][index] is the index-th feature of label
+	Features [][][]int32
+}
+
+/*
+	NewIntFeatureSet builds an *IntFeatureSet from rows of ints and their labels
+	in [0, n), where n-1 is the largest label. The rows are converted into
+	int32s, so an error is returned if a value is out of the int32 range, as
+	well as if the rows do not all have the same dimension.
+*/
+func NewIntFeatureSet(rows [][]int, labels []int) (*IntFeatureSet, error) {
+	rows32 := make([][]int32, len(rows))
+	for i, row := range rows {
+		rows32[i] = make([]int32, len(row))
+		for k, v := range row {
+			if v < math.MinInt32 || v > math.MaxInt32 {
+				return nil, fmt.Errorf("row %d: value %d of dimension %d is out of the int32 range", i, v, k)
+			}
+			rows32[i][k] = int32(v)
+		}
+	}
+	return NewInt32FeatureSet(rows32, labels)
+}
+
+/*
+	NewInt32FeatureSet is like NewIntFeatureSet but for rows of int32s, which
+	are referred to, not copied.
+*/
+func NewInt32FeatureSet(rows [][]int32, labels []int) (*IntFeatureSet, error) {
+	if len(rows) != len(labels) {
+		return nil, fmt.Errorf("%d rows but %d labels", len(rows), len(labels))
+	}
+
+	ifs := &IntFeatureSet{}
+	for i, row := range rows {
+		if labels[i] < 0 {
+			return nil, fmt.Errorf("row %d has a negative label %d", i, labels[i])
+		}
+		if i == 0 {
+			ifs.FeatureDim = len(row)
+		} else if len(row) != ifs.FeatureDim {
+			return nil, fmt.Errorf("row %d has dimension %d, expected %d", i, len(row), ifs.FeatureDim)
+		}
+		for labels[i] >= len(ifs.Features) {
+			ifs.Features = append(ifs.Features, nil)
+		}
+		ifs.Features[labels[i]] = append(ifs.Features[labels[i]], row)
+	}
+
+	return ifs, nil
+}
+
+// Implementation of LabeledFeatureSet.Dim
+func (ifs *IntFeatureSet) Dim() int {
+	return ifs.FeatureDim
+}
+
+// Implementation of LabeledFeatureSet.LabelCount
+func (ifs *IntFeatureSet) LabelCount() int {
+	return len(ifs.Features)
+}
+
+// Implementation of LabeledFeatureSet.FeatureCount
+func (ifs *IntFeatureSet) FeatureCount(label int) int {
+	return len(ifs.Features[label])
+}
+
+// Implementation of LabeledFeatureSet.FetchFeature
+func (ifs *IntFeatureSet) FetchFeature(label, index int, x []float64) {
+	for k, v := range ifs.Features[label][index] {
+		x[k] = float64(v)
+	}
+}
@@ -0,0 +1,57 @@
+package pr
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestIntFeatureSet(t *testing.T) {
+	rows := [][]int{{1, 2}, {3, 4}, {-5, 6}, {7, 1 << 30}}
+	ifs, err := NewIntFeatureSet(rows, []int{1, 0, 1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ifs.Dim() != 2 || ifs.LabelCount() != 3 {
+		t.Fatalf("dim %d and %d labels, expected 2 and 3", ifs.Dim(), ifs.LabelCount())
+	}
+
+	want := [][][]float64{{{3, 4}}, {{1, 2}, {-5, 6}}, {{7, 1 << 30}}}
+	x := make([]float64, 2)
+	for lbl := range want {
+		if cnt := ifs.FeatureCount(lbl); cnt != len(want[lbl]) {
+			t.Errorf("label %d has %d features, expected %d", lbl, cnt, len(want[lbl]))
+			continue
+		}
+		for i := range want[lbl] {
+			ifs.FetchFeature(lbl, i, x)
+			if !reflect.DeepEqual(x, want[lbl][i]) {
+				t.Errorf("feature %d of label %d is %v, expected %v", i, lbl, x, want[lbl][i])
+			}
+		}
+	}
+
+	if _, err := NewIntFeatureSet([][]int{{1, 2}, {3}}, []int{0, 0}); err == nil {
+		t.Error("expected an error for rows of different dimensions")
+	}
+	if _, err := NewIntFeatureSet([][]int{{1, 2}, {3, math.MaxInt32 + 1}}, []int{0, 0}); err == nil {
+		t.Error("expected an error for a value out of the int32 range")
+	}
+	if _, err := NewIntFeatureSet([][]int{{math.MinInt32, 2}}, []int{0}); err != nil {
+		t.Errorf("unexpected error for math.MinInt32: %v", err)
+	}
+}
+
+func TestNewInt32FeatureSetRefersToRows(t *testing.T) {
+	rows := [][]int32{{1, 2}, {3, 4}}
+	ifs, err := NewInt32FeatureSet(rows, []int{0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows[1][0] = 9
+	x := make([]float64, 2)
+	ifs.FetchFeature(0, 1, x)
+	if x[0] != 9 {
+		t.Errorf("feature is %v, expected the modified row [9 4]", x)
+	}
+}
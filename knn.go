@@ -0,0 +1,237 @@
+package pr
+
+import (
+	"math"
+	"sync"
+)
+
+/*
+	A *KNNClassifier classifies a feature by the labels of its K nearest
+	training features, in Euclidean distance or a custom Distance.
+*/
+type KNNClassifier struct {
+	// the training features
+	Features [][]float64
+	// the labels of Features
+	Labels []int
+	// the number of labels
+	LabelCount int
+	// the number of neighbors, clamped to the number of training features
+	K int
+	// if true, a neighbor votes with weight 1/distance instead of 1
+	DistanceWeighted bool
+	// if non-nil, the distance between two features, used instead of the
+	// Euclidean distance
+	Distance func(a, b []float64) float64
+	// if non-nil, TrainDistances[i][j] is the distance between the training
+	// features i and j. See CacheTrainDistances
+	TrainDistances [][]float64
+}
+
+// a tiny distance avoiding division by zero for distance weighted voting
+const knnMinDistance = 1e-12
+
+// sqDist returns the squared Euclidean distance between a and b.
+func sqDist(a, b []float64) float64 {
+	d := 0.
+	for k := range a {
+		v := a[k] - b[k]
+		d += v * v
+	}
+	return d
+}
+
+// distance returns the distance between a and b.
+func (knn *KNNClassifier) distance(a, b []float64) float64 {
+	if knn.Distance != nil {
+		return knn.Distance(a, b)
+	}
+	return math.Sqrt(sqDist(a, b))
+}
+
+/*
+	nearest returns the indices and the distances of the K nearest training
+	features, nearest first, given the distances to all training features.
+	The training feature skip (e.g. the query itself), if non-negative, is
+	excluded.
+*/
+func (knn *KNNClassifier) nearest(allDists []float64, skip int) (indices []int, dists []float64) {
+	all := make([]int, 0, len(allDists))
+	for i := range allDists {
+		if i != skip {
+			all = append(all, i)
+		}
+	}
+	indices = topK(len(all), knn.K, func(a, b int) bool {
+		if da, db := allDists[all[a]], allDists[all[b]]; da != db {
+			return da < db
+		}
+		return a < b
+	})
+	dists = make([]float64, len(indices))
+	for i, a := range indices {
+		indices[i] = all[a]
+		dists[i] = allDists[all[a]]
+	}
+	return indices, dists
+}
+
+/*
+	neighbors returns the indices and the distances of the K nearest training
+	features of x, nearest first.
+*/
+func (knn *KNNClassifier) neighbors(x []float64) (indices []int, dists []float64) {
+	allDists := make([]float64, len(knn.Features))
+	for i, f := range knn.Features {
+		allDists[i] = knn.distance(x, f)
+	}
+	return knn.nearest(allDists, -1)
+}
+
+/*
+	CacheTrainDistances computes TrainDistances, the distances between all pairs
+	of training features, which speeds up repeated TrainingNeighbors calls at
+	the cost of memory quadratic in the number of training features.
+*/
+func (knn *KNNClassifier) CacheTrainDistances() {
+	n := len(knn.Features)
+	knn.TrainDistances = make([][]float64, n)
+	for i := range knn.TrainDistances {
+		knn.TrainDistances[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			d := knn.distance(knn.Features[i], knn.Features[j])
+			knn.TrainDistances[i][j], knn.TrainDistances[j][i] = d, d
+		}
+	}
+}
+
+/*
+	TrainingNeighbors returns the indices of the K nearest training features of
+	the training feature i, excluding i itself, nearest first. TrainDistances is
+	used if cached.
+*/
+func (knn *KNNClassifier) TrainingNeighbors(i int) []int {
+	allDists := knn.TrainDistances
+	if allDists == nil {
+		row := make([]float64, len(knn.Features))
+		for j, f := range knn.Features {
+			row[j] = knn.distance(knn.Features[i], f)
+		}
+		indices, _ := knn.nearest(row, i)
+		return indices
+	}
+	indices, _ := knn.nearest(allDists[i], i)
+	return indices
+}
+
+/*
+	vote returns the normalized (weighted) votes of the neighbors for every
+	label. Uniform probabilities are returned if there is no neighbor.
+*/
+func (knn *KNNClassifier) vote(indices []int, dists []float64) []float64 {
+	probs := make([]float64, knn.LabelCount)
+	total := 0.
+	for i, idx := range indices {
+		w := 1.
+		if knn.DistanceWeighted {
+			w = 1. / (dists[i] + knnMinDistance)
+		}
+		probs[knn.Labels[idx]] += w
+		total += w
+	}
+
+	for lbl := range probs {
+		if total > 0 {
+			probs[lbl] /= total
+		} else {
+			probs[lbl] = 1. / float64(knn.LabelCount)
+		}
+	}
+	return probs
+}
+
+/*
+	ClassifyProb returns the (weighted) fraction of the K nearest neighbors of x
+	of every label. Implementation of ProbClassifier.ClassifyProb.
+*/
+func (knn *KNNClassifier) ClassifyProb(x []float64) []float64 {
+	return knn.vote(knn.neighbors(x))
+}
+
+// Implementation of Classifier.Classify. Ties are broken by the smallest
+// label.
+func (knn *KNNClassifier) Classify(x []float64) int {
+	return argmax(knn.ClassifyProb(x))
+}
+
+/*
+	ClassifyBatchParallel classifies every feature in xs in workers goroutines
+	and returns the labels in the order of xs. It needs no locking since knn is
+	only read, which requires Distance, if set, to be safe for concurrent use.
+*/
+func (knn *KNNClassifier) ClassifyBatchParallel(xs [][]float64, workers int) []int {
+	labels := make([]int, len(xs))
+	if workers < 1 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	chunk := (len(xs) + workers - 1) / workers
+	for start := 0; start < len(xs); start += chunk {
+		end := start + chunk
+		if end > len(xs) {
+			end = len(xs)
+		}
+
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				labels[i] = knn.Classify(xs[i])
+			}
+		}(start, end)
+	}
+	wg.Wait()
+
+	return labels
+}
+
+/*
+	The trainer for a k-nearest-neighbor classifier, which memorizes all
+	training features.
+*/
+type KNNTrainer struct {
+	// the number of neighbors
+	K int
+	// if true, neighbors vote with weight 1/distance
+	DistanceWeighted bool
+	// if non-nil, a custom distance between features
+	Distance func(a, b []float64) float64
+	// if true, the distances between all training features are cached
+	CacheDistances bool
+}
+
+/*
+	KNNTrain returns a *KNNClassifier holding all features in lfs.
+*/
+func KNNTrain(lfs LabeledFeatureSet, k int, distanceWeighted bool) *KNNClassifier {
+	return &KNNClassifier{
+		Features:         FeatureMatrix(lfs),
+		Labels:           FeatureLabels(lfs),
+		LabelCount:       lfs.LabelCount(),
+		K:                k,
+		DistanceWeighted: distanceWeighted,
+	}
+}
+
+// Implementation of Trainer.Train
+func (kt *KNNTrainer) Train(lfs LabeledFeatureSet) Classifier {
+	knn := KNNTrain(lfs, kt.K, kt.DistanceWeighted)
+	knn.Distance = kt.Distance
+	if kt.CacheDistances {
+		knn.CacheTrainDistances()
+	}
+	return knn
+}
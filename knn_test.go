@@ -0,0 +1,65 @@
+package pr
+
+import (
+	"math"
+	"runtime"
+	"testing"
+)
+
+func BenchmarkClassifyBatchParallel(b *testing.B) {
+	sfs := randomSet(1, 1000, []float64{0, 0, 0, 0, 0}, []float64{1, 1, 1, 1, 1}, []float64{2, 0, 2, 0, 2})
+	knn := KNNTrain(sfs, 5, false)
+	xs := FeatureMatrix(randomSet(2, 100, []float64{0, 0, 0, 0, 0}, []float64{1, 1, 1, 1, 1}, []float64{2, 0, 2, 0, 2}))
+
+	b.Run("serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, x := range xs {
+				knn.Classify(x)
+			}
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			knn.ClassifyBatchParallel(xs, runtime.NumCPU())
+		}
+	})
+}
+
+func TestKNNClassifyProb(t *testing.T) {
+	sfs := &SliceFeatureSet{
+		FeatureDim: 1,
+		Features:   [][][]float64{{{0}, {1}}, {{3}, {4}}, {{10}}},
+	}
+	/* x coincides with the training feature {1} */
+	x := []float64{1}
+
+	for _, weighted := range []bool{false, true} {
+		for _, k := range []int{1, 3, 5, 100} {
+			probs := KNNTrain(sfs, k, weighted).ClassifyProb(x)
+			sum := 0.
+			for _, p := range probs {
+				if math.IsNaN(p) || p < 0 {
+					t.Errorf("weighted %v, k %d: invalid probabilities %v", weighted, k, probs)
+				}
+				sum += p
+			}
+			if math.Abs(sum-1) > 1e-12 {
+				t.Errorf("weighted %v, k %d: probabilities %v sum to %v", weighted, k, probs, sum)
+			}
+		}
+	}
+
+	if probs := KNNTrain(sfs, 3, false).ClassifyProb(x); !floatsEqual(probs, []float64{2. / 3, 1. / 3, 0}, 1e-12) {
+		t.Errorf("k 3: probabilities %v, expected [2/3 1/3 0]", probs)
+	}
+	/* the zero-distance neighbor dominates the weighted vote */
+	if probs := KNNTrain(sfs, 3, true).ClassifyProb(x); !(probs[0] > 1-1e-9) {
+		t.Errorf("k 3, weighted: probabilities %v, expected almost all on label 0", probs)
+	}
+	/* k above the 5 training features is clamped to all of them */
+	for _, k := range []int{5, 6, 100} {
+		if probs := KNNTrain(sfs, k, false).ClassifyProb(x); !floatsEqual(probs, []float64{0.4, 0.4, 0.2}, 1e-12) {
+			t.Errorf("k %d: probabilities %v, expected [0.4 0.4 0.2]", k, probs)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package pr
+
+import (
+	"fmt"
+	"sort"
+)
+
+/*
+	RemapLabels encodes arbitrary integer labels as contiguous labels 0..N-1,
+	in ascending order of the raw labels. mapped[i] is the label of raw[i],
+	forward maps a raw label to its label, and backward[label] is the raw label.
+*/
+func RemapLabels(raw []int) (mapped []int, forward map[int]int, backward []int) {
+	forward = make(map[int]int)
+	for _, r := range raw {
+		if _, ok := forward[r]; !ok {
+			forward[r] = 0
+			backward = append(backward, r)
+		}
+	}
+	sort.Ints(backward)
+	for lbl, r := range backward {
+		forward[r] = lbl
+	}
+
+	mapped = make([]int, len(raw))
+	for i, r := range raw {
+		mapped[i] = forward[r]
+	}
+	return mapped, forward, backward
+}
+
+/*
+	SliceFeatureSetFromRaw builds a *SliceFeatureSet from features with
+	arbitrary integer labels, remapped by RemapLabels. backward[label] is the
+	raw label of a label of the result. All features must have the same
+	dimension.
+*/
+func SliceFeatureSetFromRaw(features [][]float64, rawLabels []int) (sfs *SliceFeatureSet, backward []int, err error) {
+	if len(features) != len(rawLabels) {
+		return nil, nil, fmt.Errorf("%d features but %d labels", len(features), len(rawLabels))
+	}
+
+	mapped, _, backward := RemapLabels(rawLabels)
+	sfs = &SliceFeatureSet{Features: make([][][]float64, len(backward))}
+	for i, x := range features {
+		if i == 0 {
+			sfs.FeatureDim = len(x)
+		} else if len(x) != sfs.FeatureDim {
+			return nil, nil, fmt.Errorf("feature %d has dimension %d, expected %d", i, len(x), sfs.FeatureDim)
+		}
+		sfs.Features[mapped[i]] = append(sfs.Features[mapped[i]], x)
+	}
+
+	return sfs, backward, nil
+}
@@ -0,0 +1,227 @@
+package pr
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+	LDATrain trains a *GaussianClassifier for linear discriminant analysis:
+	every label has its own mean while all labels share one covariance matrix,
+	the pooled within-class scatter divided by the number of features minus the
+	number of labels. The decision boundaries of the result are linear.
+
+	shrinkage, in [0, 1], shrinks the pooled covariance toward its diagonal
+	before inversion, which regularizes it when there are few features:
+
+	  Sigma' = (1 - shrinkage) * Sigma + shrinkage * diag(Sigma)
+
+	shrinkage 0 gives plain LDA.
+*/
+func LDATrain(lfs LabeledFeatureSet, shrinkage float64) (*GaussianClassifier, error) {
+	if !(shrinkage >= 0 && shrinkage <= 1) {
+		return nil, fmt.Errorf("shrinkage %v is not in [0, 1]", shrinkage)
+	}
+
+	lblCnt := lfs.LabelCount()
+	dim := lfs.Dim()
+	clsfr := &GaussianClassifier{
+		Means:    make([][]float64, lblCnt),
+		Precs:    make([][]float64, lblCnt),
+		LogCoefs: make([]float64, lblCnt),
+	}
+
+	x := make([]float64, dim)
+
+	sigma := make([]float64, dim*dim)
+	total := 0
+	for lbl := range clsfr.Means {
+		cnt := lfs.FeatureCount(lbl)
+		if cnt == 0 {
+			return nil, fmt.Errorf("label %d has no features", lbl)
+		}
+		clsfr.Means[lbl] = featureMean(lfs, lbl, x)
+		addScatter(lfs, lbl, clsfr.Means[lbl], x, sigma)
+		total += cnt
+	}
+	if total > lblCnt {
+		for i := range sigma {
+			sigma[i] /= float64(total - lblCnt)
+		}
+	}
+	symmetrize(sigma, dim)
+	shrinkToDiagonal(sigma, dim, shrinkage)
+
+	prec, logCoef, err := gaussianPrec(sigma, dim)
+	if err != nil {
+		return nil, errors.New("pooled covariance matrix is singular")
+	}
+
+	for lbl := range clsfr.Precs {
+		clsfr.Precs[lbl] = append([]float64(nil), prec...)
+		clsfr.LogCoefs[lbl] = logCoef
+	}
+
+	return clsfr, nil
+}
+
+/*
+	The trainer for linear discriminant analysis. See LDATrain.
+*/
+type LDATrainer struct {
+	// the shrinkage toward the diagonal of the pooled covariance, in [0, 1]
+	Shrinkage float64
+}
+
+// Implementation of Trainer.Train. nil is returned if training fails.
+func (lt *LDATrainer) Train(lfs LabeledFeatureSet) Classifier {
+	gc, err := LDATrain(lfs, lt.Shrinkage)
+	if err != nil {
+		return nil
+	}
+	return gc
+}
+
+/*
+	sharedPrec returns the precision shared by all labels with the feature
+	weights folded in, or nil if labels have different precisions.
+*/
+func (gc *GaussianClassifier) sharedPrec() []float64 {
+	if len(gc.Precs) == 0 {
+		return nil
+	}
+	prec := gc.Precs[0]
+	for _, p := range gc.Precs[1:] {
+		if len(p) != len(prec) {
+			return nil
+		}
+		for i := range p {
+			if p[i] != prec[i] {
+				return nil
+			}
+		}
+	}
+	return gc.weightedPrec(prec)
+}
+
+/*
+	LinearCoefficients returns the linear discriminant functions of a model
+	whose labels share one precision matrix (e.g. trained by LDATrain). The
+	label of a feature x is then the one maximizing
+
+	  weights[label] . x + bias[label],
+
+	which gives the same decision as Classify since the quadratic term x'Px is
+	common to all labels. Feature weights, if set, are folded into the
+	precision. ok is false if the labels have different precisions, i.e. the
+	model is quadratic.
+*/
+func (gc *GaussianClassifier) LinearCoefficients() (weights [][]float64, bias []float64, ok bool) {
+	prec := gc.sharedPrec()
+	if prec == nil {
+		return nil, nil, false
+	}
+
+	dim := gc.Dim()
+	weights = make([][]float64, gc.LabelCount())
+	bias = make([]float64, gc.LabelCount())
+	for lbl, mean := range gc.Means {
+		/* (x - mu)'P(x - mu) = x'Px - 2 mu'P x + mu'P mu */
+		w := make([]float64, dim)
+		b := gc.LogCoefs[lbl]
+		for k := 0; k < dim; k++ {
+			pm := 0.
+			for l := 0; l < dim; l++ {
+				pm += prec[k*dim+l] * mean[l]
+			}
+			w[k] = -2. * pm
+			b += mean[k] * pm
+		}
+		if gc.LogPrior != nil {
+			b += gc.LogPrior[lbl]
+		}
+		weights[lbl], bias[lbl] = w, b
+	}
+
+	return weights, bias, true
+}
+
+/*
+	QuadraticBoundary returns the quadratic discriminant between the labels i and
+	j,
+
+	  g(x) = x'Ax + b'x + c,
+
+	which is LogPosterior(i, x) - LogPosterior(j, x). The decision boundary of
+	the two labels is g(x) = 0, and x is more likely of label i where g(x) > 0.
+	A is zero if the two labels share one precision matrix.
+*/
+func (gc *GaussianClassifier) QuadraticBoundary(i, j int) (A [][]float64, b []float64, c float64) {
+	coeffs := gc.ExportCoefficients()
+	dim := coeffs.Dim
+
+	A = make([][]float64, dim)
+	for k := range A {
+		A[k] = make([]float64, dim)
+	}
+	b = make([]float64, dim)
+	c = coeffs.LogCoefs[i] - coeffs.LogCoefs[j] + coeffs.LogPrior[i] - coeffs.LogPrior[j]
+
+	add := func(lbl int, sign float64) {
+		/* (x - mu)'P(x - mu) = x'Px - 2 mu'P x + mu'P mu */
+		prec, mean := coeffs.Precs[lbl], coeffs.Means[lbl]
+		for k := 0; k < dim; k++ {
+			pm := 0.
+			for l := 0; l < dim; l++ {
+				A[k][l] += sign * prec[k*dim+l]
+				pm += prec[k*dim+l] * mean[l]
+			}
+			b[k] -= sign * 2. * pm
+			c += sign * mean[k] * pm
+		}
+	}
+	add(i, 1)
+	add(j, -1)
+
+	return A, b, c
+}
+
+/*
+	ProjectLDA returns the projections of x onto the discriminant directions of
+	a model whose labels share one covariance matrix Sigma (e.g. trained by
+	LDATrain), one per label but the first:
+
+	  inv(Sigma) * (mu[label] - mu[0]),  label = 1..labels-1
+
+	These span the Fisher discriminant subspace, so the classification depends
+	on x only through the projections; projecting the means gives the
+	per-label centers, e.g. for plotting the separation. With two labels it is
+	the classic 1D Fisher discriminant. Feature weights, if set, are folded into
+	inv(Sigma). An error is returned for a model whose
+	labels have different covariances, i.e. quadratic, or for x of a wrong
+	dimension.
+*/
+func (gc *GaussianClassifier) ProjectLDA(x []float64) ([]float64, error) {
+	prec := gc.sharedPrec()
+	if prec == nil {
+		return nil, errors.New("labels have different covariances, no linear projection applies")
+	}
+	dim := gc.Dim()
+	if len(x) != dim {
+		return nil, fmt.Errorf("feature has dimension %d, expected %d", len(x), dim)
+	}
+
+	proj := make([]float64, gc.LabelCount()-1)
+	for i := range proj {
+		/* inv(Sigma) = -2 * prec */
+		mean, base := gc.Means[i+1], gc.Means[0]
+		for k := 0; k < dim; k++ {
+			w := 0.
+			for l := 0; l < dim; l++ {
+				w += -2. * prec[k*dim+l] * (mean[l] - base[l])
+			}
+			proj[i] += w * x[k]
+		}
+	}
+	return proj, nil
+}
@@ -0,0 +1,65 @@
+package pr
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLinearCoefficientsFeatureWeights(t *testing.T) {
+	sfs := randomSet(1, 200, []float64{0, 0}, []float64{2, 3}, []float64{-1, 4})
+	gc, err := LDATrain(sfs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gc.SetFeatureWeights([]float64{1, 1e-4}); err != nil {
+		t.Fatal(err)
+	}
+
+	weights, bias, ok := gc.LinearCoefficients()
+	if !ok {
+		t.Fatal("expected a linear model")
+	}
+	for x0 := -3.; x0 <= 5; x0 += 0.5 {
+		for x1 := -3.; x1 <= 7; x1 += 0.5 {
+			x := []float64{x0, x1}
+			best, bestScore := -1, math.Inf(-1)
+			for lbl, w := range weights {
+				if s := w[0]*x[0] + w[1]*x[1] + bias[lbl]; s > bestScore {
+					best, bestScore = lbl, s
+				}
+			}
+			if lbl := gc.Classify(x); lbl != best {
+				t.Errorf("x = %v: Classify gives %d, linear rule gives %d", x, lbl, best)
+			}
+		}
+	}
+}
+
+func TestProjectLDAFeatureWeights(t *testing.T) {
+	sfs := randomSet(2, 200, []float64{0, 0}, []float64{2, 3})
+	gc, err := LDATrain(sfs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain0, err := gc.ProjectLDA([]float64{0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gc.SetFeatureWeights([]float64{1, 1e-4}); err != nil {
+		t.Fatal(err)
+	}
+
+	/* with the second dimension almost ignored, moving along it barely moves
+	   the projection */
+	p0, err := gc.ProjectLDA([]float64{0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p1, err := gc.ProjectLDA([]float64{0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, plain := math.Abs(p1[0]-p0[0]), math.Abs(plain0[0]); d > 1e-2*plain {
+		t.Errorf("weighted projection moves by %g along a down-weighted dimension, unweighted by %g", d, plain)
+	}
+}
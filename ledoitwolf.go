@@ -0,0 +1,106 @@
+package pr
+
+import (
+	"fmt"
+)
+
+/*
+	LedoitWolfGaussianTrain trains a *GaussianClassifier like GaussianTrain but
+	shrinks the covariance matrix S of every label toward mu*I, where mu is the
+	mean of the diagonal of S, with the Ledoit-Wolf estimate of the optimal
+	intensity lambda:
+
+	  Sigma = (1 - lambda) * S + lambda * mu * I
+
+	Here S is the maximum-likelihood covariance (divided by the number of
+	features), as in the estimator. The lambda of every label is recorded in
+	Shrinkages. The covariances are well-conditioned without any tuning, even
+	with fewer features than dimensions.
+*/
+func LedoitWolfGaussianTrain(lfs LabeledFeatureSet) (*GaussianClassifier, error) {
+	lblCnt := lfs.LabelCount()
+	dim := lfs.Dim()
+	clsfr := &GaussianClassifier{
+		Means:      make([][]float64, lblCnt),
+		Precs:      make([][]float64, lblCnt),
+		LogCoefs:   make([]float64, lblCnt),
+		Shrinkages: make([]float64, lblCnt),
+	}
+
+	x := make([]float64, dim)
+	sigma := make([]float64, dim*dim)
+	for lbl := range clsfr.Means {
+		cnt := lfs.FeatureCount(lbl)
+		if cnt < 2 {
+			return nil, fmt.Errorf("label %d has %d features, at least 2 are needed", lbl, cnt)
+		}
+		mean := featureMean(lfs, lbl, x)
+
+		for i := range sigma {
+			sigma[i] = 0.
+		}
+		addScatter(lfs, lbl, mean, x, sigma)
+		for i := range sigma {
+			sigma[i] /= float64(cnt)
+		}
+		symmetrize(sigma, dim)
+
+		mu := 0.
+		for k := 0; k < dim; k++ {
+			mu += sigma[k*dim+k]
+		}
+		mu /= float64(dim)
+
+		/* delta2 = ||S - mu*I||^2 / dim */
+		delta2 := 0.
+		for k := 0; k < dim; k++ {
+			for l := 0; l < dim; l++ {
+				d := sigma[k*dim+l]
+				if k == l {
+					d -= mu
+				}
+				delta2 += d * d
+			}
+		}
+		delta2 /= float64(dim)
+
+		/* beta2 = sum_i ||x_i*x_i' - S||^2 / dim / cnt^2, x_i centered */
+		beta2 := 0.
+		for i := 0; i < cnt; i++ {
+			lfs.FetchFeature(lbl, i, x)
+			for k := 0; k < dim; k++ {
+				for l := 0; l < dim; l++ {
+					d := (x[k]-mean[k])*(x[l]-mean[l]) - sigma[k*dim+l]
+					beta2 += d * d
+				}
+			}
+		}
+		beta2 /= float64(dim) * float64(cnt) * float64(cnt)
+
+		lambda := 0.
+		if delta2 > 0 {
+			if beta2 > delta2 {
+				beta2 = delta2
+			}
+			lambda = beta2 / delta2
+		}
+		for k := 0; k < dim; k++ {
+			for l := 0; l < dim; l++ {
+				sigma[k*dim+l] *= 1 - lambda
+			}
+			sigma[k*dim+k] += lambda * mu
+		}
+
+		prec, logCoef, err := gaussianPrec(sigma, dim)
+		if err != nil {
+			return nil, fmt.Errorf("label %d: covariance matrix is singular: %v", lbl, err)
+		}
+
+		clsfr.Means[lbl] = mean
+		clsfr.Precs[lbl] = prec
+		clsfr.LogCoefs[lbl] = logCoef
+		clsfr.Shrinkages[lbl] = lambda
+	}
+
+	return clsfr, nil
+}
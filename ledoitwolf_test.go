@@ -0,0 +1,47 @@
+package pr
+
+import (
+	"testing"
+)
+
+func TestLedoitWolfGaussianTrain(t *testing.T) {
+	sfs := &SliceFeatureSet{
+		FeatureDim: 2,
+		Features: [][][]float64{
+			{{3, 1}, {-3, -1}, {1, -1}, {-1, 1}},
+			{{2, 0}, {-1, 1}, {-1, -1}},
+		},
+	}
+	gc, err := LedoitWolfGaussianTrain(sfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	/*
+		Label 0: S = [5 1; 1 1], mu = 3, delta2 = ||S - 3I||^2/2 = 5 and every
+		||x*x' - S||^2 is 24, so beta2 = 4*24/2/16 = 3 and lambda = 0.6, giving
+		Sigma = [3.8 0.4; 0.4 2.2] with determinant 8.2.
+
+		Label 1: S = diag(2, 2/3), delta2 = 4/9 and beta2 = 16/27 is clipped to
+		delta2, so lambda = 1 and Sigma = 4/3*I.
+	*/
+	if want := []float64{0.6, 1}; !floatsEqual(gc.Shrinkages, want, 1e-12) {
+		t.Errorf("shrinkages %v, expected %v", gc.Shrinkages, want)
+	}
+	wantPrecs := [][]float64{
+		{-0.5 * 2.2 / 8.2, 0.5 * 0.4 / 8.2, 0.5 * 0.4 / 8.2, -0.5 * 3.8 / 8.2},
+		{-0.375, 0, 0, -0.375},
+	}
+	for lbl, want := range wantPrecs {
+		if !floatsEqual(gc.Means[lbl], []float64{0, 0}, 1e-12) {
+			t.Errorf("label %d: mean %v, expected the origin", lbl, gc.Means[lbl])
+		}
+		if !floatsEqual(gc.Precs[lbl], want, 1e-12) {
+			t.Errorf("label %d: precision %v, expected %v", lbl, gc.Precs[lbl], want)
+		}
+	}
+
+	sfs.Features[1] = sfs.Features[1][:1]
+	if _, err := LedoitWolfGaussianTrain(sfs); err == nil {
+		t.Error("expected an error for a label with one feature")
+	}
+}
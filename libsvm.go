@@ -0,0 +1,92 @@
+package pr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+	LoadLibSVM reads features in the libsvm format from r, one feature per line:
+
+	  label index:value index:value ...
+
+	Indices are 1-based and must be in 1..dim. Omitted components are zero.
+	Empty lines are ignored.
+
+	The distinct labels in the file are sorted and remapped to 0..N-1; labels[i]
+	is the original label of label i in the returned feature set. Errors on
+	malformed input contain the line number.
+*/
+func LoadLibSVM(r io.Reader, dim int) (*SliceFeatureSet, []float64, error) {
+	type sample struct {
+		label float64
+		x     []float64
+	}
+	var samples []sample
+
+	br := bufio.NewReader(r)
+	for lineNo := 1; ; lineNo++ {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, nil, err
+		}
+
+		if fields := strings.Fields(line); len(fields) > 0 {
+			label, perr := strconv.ParseFloat(fields[0], 64)
+			if perr != nil {
+				return nil, nil, fmt.Errorf("line %d: invalid label %q", lineNo, fields[0])
+			}
+
+			x := make([]float64, dim)
+			for _, f := range fields[1:] {
+				colon := strings.IndexByte(f, ':')
+				if colon < 0 {
+					return nil, nil, fmt.Errorf("line %d: expected index:value, got %q", lineNo, f)
+				}
+				idx, perr := strconv.Atoi(f[:colon])
+				if perr != nil || idx < 1 || idx > dim {
+					return nil, nil, fmt.Errorf("line %d: invalid index %q, expected 1..%d", lineNo, f[:colon], dim)
+				}
+				val, perr := strconv.ParseFloat(f[colon+1:], 64)
+				if perr != nil {
+					return nil, nil, fmt.Errorf("line %d: invalid value %q", lineNo, f[colon+1:])
+				}
+				x[idx-1] = val
+			}
+
+			samples = append(samples, sample{label, x})
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	var labels []float64
+	mapping := make(map[float64]int)
+	for _, s := range samples {
+		if _, ok := mapping[s.label]; !ok {
+			mapping[s.label] = len(labels)
+			labels = append(labels, s.label)
+		}
+	}
+	sort.Float64s(labels)
+	for i, l := range labels {
+		mapping[l] = i
+	}
+
+	sfs := &SliceFeatureSet{
+		FeatureDim: dim,
+		Features:   make([][][]float64, len(labels)),
+	}
+	for _, s := range samples {
+		lbl := mapping[s.label]
+		sfs.Features[lbl] = append(sfs.Features[lbl], s.x)
+	}
+
+	return sfs, labels, nil
+}
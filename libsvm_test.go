@@ -0,0 +1,49 @@
+package pr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadLibSVM(t *testing.T) {
+	const data = "" +
+		"+1 1:0.5 3:2\n" +
+		"\n" +
+		"-1 2:-1\n" +
+		"3 1:1 2:2 3:3\n" +
+		"+1"
+	sfs, labels, err := LoadLibSVM(strings.NewReader(data), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(labels, []float64{-1, 1, 3}) {
+		t.Errorf("labels %v, expected [-1 1 3]", labels)
+	}
+	want := [][][]float64{
+		{{0, -1, 0}},
+		{{0.5, 0, 2}, {0, 0, 0}},
+		{{1, 2, 3}},
+	}
+	if !reflect.DeepEqual(sfs.Features, want) {
+		t.Errorf("features %v, expected %v", sfs.Features, want)
+	}
+}
+
+func TestLoadLibSVMMalformed(t *testing.T) {
+	for _, c := range []struct {
+		data, errPart string
+	}{
+		{"1 1:1\nx 1:2\n", "line 2: invalid label"},
+		{"1 1:1\n\n1 2\n", "line 3: expected index:value"},
+		{"1 0:1\n", "line 1: invalid index"},
+		{"1 4:1\n", "line 1: invalid index"},
+		{"1 a:1\n", "line 1: invalid index"},
+		{"1 1:1 2:b\n", "line 1: invalid value"},
+	} {
+		_, _, err := LoadLibSVM(strings.NewReader(c.data), 3)
+		if err == nil || !strings.Contains(err.Error(), c.errPart) {
+			t.Errorf("%q: error %v, expected one containing %q", c.data, err, c.errPart)
+		}
+	}
+}
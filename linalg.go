@@ -0,0 +1,162 @@
+package pr
+
+import (
+	"math"
+)
+
+/*
+	cholesky computes the Cholesky factorization of a symmetric positive
+	definite dim x dim matrix a (row-major), i.e. the lower triangular matrix L
+	with a = L * L^T. ok is false if a is not positive definite.
+*/
+func cholesky(a []float64, dim int) (L []float64, ok bool) {
+	L = make([]float64, dim*dim)
+	for k := 0; k < dim; k++ {
+		for l := 0; l <= k; l++ {
+			s := a[k*dim+l]
+			for m := 0; m < l; m++ {
+				s -= L[k*dim+m] * L[l*dim+m]
+			}
+			if l == k {
+				if s <= 0 || math.IsNaN(s) {
+					return nil, false
+				}
+				L[k*dim+k] = math.Sqrt(s)
+			} else {
+				L[k*dim+l] = s / L[l*dim+l]
+			}
+		}
+	}
+	return L, true
+}
+
+// choleskyLogDet returns the logarithm of the determinant of L * L^T.
+func choleskyLogDet(L []float64, dim int) float64 {
+	logDet := 0.
+	for k := 0; k < dim; k++ {
+		logDet += math.Log(L[k*dim+k])
+	}
+	return 2. * logDet
+}
+
+/*
+	forwardSubst solves L * y = b for y, where L is a lower triangular dim x
+	dim matrix.
+*/
+func forwardSubst(L, b []float64, dim int) []float64 {
+	y := make([]float64, dim)
+	for k := 0; k < dim; k++ {
+		s := b[k]
+		for l := 0; l < k; l++ {
+			s -= L[k*dim+l] * y[l]
+		}
+		y[k] = s / L[k*dim+k]
+	}
+	return y
+}
+
+/*
+	choleskyInverse returns the inverse of L * L^T, given the Cholesky factor L,
+	as inv(L)^T * inv(L).
+*/
+func choleskyInverse(L []float64, dim int) []float64 {
+	// inverse of the lower triangular L, column by column
+	Linv := make([]float64, dim*dim)
+	for l := 0; l < dim; l++ {
+		Linv[l*dim+l] = 1. / L[l*dim+l]
+		for k := l + 1; k < dim; k++ {
+			s := 0.
+			for m := l; m < k; m++ {
+				s -= L[k*dim+m] * Linv[m*dim+l]
+			}
+			Linv[k*dim+l] = s / L[k*dim+k]
+		}
+	}
+
+	inv := make([]float64, dim*dim)
+	for k := 0; k < dim; k++ {
+		for l := k; l < dim; l++ {
+			s := 0.
+			for m := l; m < dim; m++ {
+				s += Linv[m*dim+k] * Linv[m*dim+l]
+			}
+			inv[k*dim+l], inv[l*dim+k] = s, s
+		}
+	}
+	return inv
+}
+
+/*
+	luLogAbsDet returns the logarithm of the absolute determinant of a dim x dim
+	matrix a (row-major), summed over the pivots of its LU factorization with
+	partial pivoting, so it stays finite where the determinant itself overflows
+	or underflows. ok is false if a is singular.
+*/
+func luLogAbsDet(a []float64, dim int) (logDet float64, ok bool) {
+	lu := append([]float64(nil), a...)
+	for k := 0; k < dim; k++ {
+		p := k
+		for i := k + 1; i < dim; i++ {
+			if math.Abs(lu[i*dim+k]) > math.Abs(lu[p*dim+k]) {
+				p = i
+			}
+		}
+		if lu[p*dim+k] == 0 || math.IsNaN(lu[p*dim+k]) {
+			return 0, false
+		}
+		if p != k {
+			for j := 0; j < dim; j++ {
+				lu[k*dim+j], lu[p*dim+j] = lu[p*dim+j], lu[k*dim+j]
+			}
+		}
+		for i := k + 1; i < dim; i++ {
+			f := lu[i*dim+k] / lu[k*dim+k]
+			for j := k + 1; j < dim; j++ {
+				lu[i*dim+j] -= f * lu[k*dim+j]
+			}
+		}
+		logDet += math.Log(math.Abs(lu[k*dim+k]))
+	}
+	return logDet, true
+}
+
+// cloneSlices returns a deep copy of a. nil is returned if a is nil.
+func cloneSlices(a [][]float64) [][]float64 {
+	if a == nil {
+		return nil
+	}
+	c := make([][]float64, len(a))
+	for i := range a {
+		c[i] = append([]float64(nil), a[i]...)
+	}
+	return c
+}
+
+/*
+	floatsEqual returns true if a and b have the same length and all elements
+	differ by at most tol.
+*/
+func floatsEqual(a, b []float64, tol float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !(math.Abs(a[i]-b[i]) <= tol) && a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// slicesEqual is floatsEqual for slices of slices.
+func slicesEqual(a, b [][]float64, tol float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !floatsEqual(a[i], b[i], tol) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,143 @@
+package pr
+
+import (
+	"fmt"
+	"sort"
+)
+
+// subMatrix returns the submatrix of the dim*dim matrix a at rows and cols.
+func subMatrix(a []float64, dim int, rows, cols []int) []float64 {
+	sub := make([]float64, 0, len(rows)*len(cols))
+	for _, r := range rows {
+		for _, c := range cols {
+			sub = append(sub, a[r*dim+c])
+		}
+	}
+	return sub
+}
+
+/*
+	Marginalize returns the model of gc over the dimensions dims only, in the
+	given order: the means and the covariance matrices restricted to dims, with
+	the precisions and the log-coefficients recomputed. Unlike masking, this is
+	the exact distribution of the remaining dimensions. The dims must be in
+	range and distinct. The prior and the feature weights of dims are kept.
+*/
+func (gc *GaussianClassifier) Marginalize(dims []int) (*GaussianClassifier, error) {
+	dim := gc.Dim()
+	seen := make([]bool, dim)
+	for _, k := range dims {
+		if k < 0 || k >= dim {
+			return nil, fmt.Errorf("dimension %d out of range [0, %d)", k, dim)
+		}
+		if seen[k] {
+			return nil, fmt.Errorf("dimension %d repeated", k)
+		}
+		seen[k] = true
+	}
+
+	lblCnt := gc.LabelCount()
+	mg := &GaussianClassifier{
+		Means:    make([][]float64, lblCnt),
+		Precs:    make([][]float64, lblCnt),
+		LogCoefs: make([]float64, lblCnt),
+		Diagonal: gc.Diagonal,
+	}
+	if gc.LogPrior != nil {
+		mg.LogPrior = append([]float64(nil), gc.LogPrior...)
+	}
+	if gc.Counts != nil {
+		mg.Counts = append([]int(nil), gc.Counts...)
+	}
+	if gc.FeatureWeights != nil {
+		mg.FeatureWeights = make([]float64, len(dims))
+		for i, k := range dims {
+			mg.FeatureWeights[i] = gc.FeatureWeights[k]
+		}
+	}
+
+	for lbl := range mg.Means {
+		sigma, err := gc.covariance(lbl)
+		if err != nil {
+			return nil, fmt.Errorf("label %d: covariance can not be reconstructed: %v", lbl, err)
+		}
+		prec, logCoef, err := gaussianPrec(subMatrix(sigma, dim, dims, dims), len(dims))
+		if err != nil {
+			return nil, fmt.Errorf("label %d: covariance matrix is singular: %v", lbl, err)
+		}
+
+		mean := make([]float64, len(dims))
+		for i, k := range dims {
+			mean[i] = gc.Means[lbl][k]
+		}
+		mg.Means[lbl] = mean
+		mg.Precs[lbl] = prec
+		mg.LogCoefs[lbl] = logCoef
+	}
+
+	return mg, nil
+}
+
+/*
+	ConditionalMean returns the expected values of the unobserved dimensions of
+	a feature of the label, in ascending order of dimension, given the values
+	of the observed dimensions:
+
+	  mu_u + Sigma_uo * inv(Sigma_oo) * (x_o - mu_o),
+
+	where u and o are the unobserved and the observed dimensions. It can be
+	used to impute missing values.
+*/
+func (gc *GaussianClassifier) ConditionalMean(label int, observed map[int]float64) ([]float64, error) {
+	dim := gc.Dim()
+	var obs, unobs []int
+	for k := 0; k < dim; k++ {
+		if _, ok := observed[k]; ok {
+			obs = append(obs, k)
+		} else {
+			unobs = append(unobs, k)
+		}
+	}
+	if len(obs) != len(observed) {
+		var bad []int
+		for k := range observed {
+			if k < 0 || k >= dim {
+				bad = append(bad, k)
+			}
+		}
+		sort.Ints(bad)
+		return nil, fmt.Errorf("dimension %d out of range [0, %d)", bad[0], dim)
+	}
+
+	mean := gc.Means[label]
+	cond := make([]float64, len(unobs))
+	for i, k := range unobs {
+		cond[i] = mean[k]
+	}
+	if len(obs) == 0 {
+		return cond, nil
+	}
+
+	sigma, err := gc.covariance(label)
+	if err != nil {
+		return nil, fmt.Errorf("covariance can not be reconstructed: %v", err)
+	}
+	prec, _, err := gaussianPrec(subMatrix(sigma, dim, obs, obs), len(obs))
+	if err != nil {
+		return nil, fmt.Errorf("covariance of the observed dimensions is singular: %v", err)
+	}
+
+	/* z = inv(Sigma_oo) * (x_o - mu_o), where prec = -1/2 * inv(Sigma_oo) */
+	z := make([]float64, len(obs))
+	for i := range obs {
+		for j, l := range obs {
+			z[i] += -2. * prec[i*len(obs)+j] * (observed[l] - mean[l])
+		}
+	}
+	for i, k := range unobs {
+		for j, l := range obs {
+			cond[i] += sigma[k*dim+l] * z[j]
+		}
+	}
+	return cond, nil
+}
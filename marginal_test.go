@@ -0,0 +1,80 @@
+package pr
+
+import (
+	"math"
+	"testing"
+)
+
+// gaussianFromCovs returns a *GaussianClassifier with the given means and
+// covariance matrices.
+func gaussianFromCovs(tb testing.TB, means, sigmas [][]float64) *GaussianClassifier {
+	gc := &GaussianClassifier{
+		Means:    means,
+		Precs:    make([][]float64, len(means)),
+		LogCoefs: make([]float64, len(means)),
+	}
+	for lbl, sigma := range sigmas {
+		prec, logCoef, err := gaussianPrec(sigma, len(means[lbl]))
+		if err != nil {
+			tb.Fatalf("label %d: %v", lbl, err)
+		}
+		gc.Precs[lbl], gc.LogCoefs[lbl] = prec, logCoef
+	}
+	return gc
+}
+
+func TestMarginalize(t *testing.T) {
+	gc := gaussianFromCovs(t, [][]float64{{1, 2, 3}}, [][]float64{{
+		2, 0.5, 0,
+		0.5, 1, 0.3,
+		0, 0.3, 1.5,
+	}})
+	mg, err := gc.Marginalize([]int{2, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{3, 1}; !floatsEqual(mg.Means[0], want, 0) {
+		t.Errorf("means %v, expected %v", mg.Means[0], want)
+	}
+	/* dimensions 2 and 0 are uncorrelated with variances 1.5 and 2 */
+	x := []float64{4, 0}
+	want := -math.Log(2*math.Pi) - 0.5*math.Log(3) - 0.5*(1/1.5+1/2.)
+	if ll := mg.LogLikelyhood(0, x); math.Abs(ll-want) > 1e-9 {
+		t.Errorf("log-likelyhood %v, expected %v", ll, want)
+	}
+
+	if _, err := gc.Marginalize([]int{0, 3}); err == nil {
+		t.Error("expected an error for an out-of-range dimension")
+	}
+	if _, err := gc.Marginalize([]int{1, 1}); err == nil {
+		t.Error("expected an error for a repeated dimension")
+	}
+}
+
+func TestConditionalMean(t *testing.T) {
+	gc := gaussianFromCovs(t, [][]float64{{1, 2, 0}}, [][]float64{{
+		2, 0.6, 0,
+		0.6, 1, 0,
+		0, 0, 1,
+	}})
+	/* 2 + 0.6/2*(3-1) for dimension 1; dimension 2 is uncorrelated */
+	cond, err := gc.ConditionalMean(0, map[int]float64{0: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{2.6, 0}; !floatsEqual(cond, want, 1e-12) {
+		t.Errorf("conditional mean %v, expected %v", cond, want)
+	}
+
+	cond, err = gc.ConditionalMean(0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{1, 2, 0}; !floatsEqual(cond, want, 0) {
+		t.Errorf("conditional mean without observations %v, expected %v", cond, want)
+	}
+
+	if _, err := gc.ConditionalMean(0, map[int]float64{0: 3, 5: 1}); err == nil {
+		t.Error("expected an error for an out-of-range dimension")
+	}
+}
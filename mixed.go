@@ -0,0 +1,197 @@
+package pr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Types of dimensions in a FeatureSchema.
+const (
+	// real values
+	ContinuousFeature = iota
+	// unordered categories encoded as integers 0..cardinality-1
+	CategoricalFeature
+	// 0 or 1
+	BinaryFeature
+)
+
+/*
+	A FeatureSchema describes the type of every dimension of features.
+*/
+type FeatureSchema struct {
+	// Types[k] is ContinuousFeature, CategoricalFeature or BinaryFeature
+	Types []int
+	// Cardinalities[k] is the number of categories of a categorical dimension
+	// k, ignored for other types
+	Cardinalities []int
+}
+
+// cardinality returns the number of categories of dimension k, 0 for a
+// continuous one.
+func (fs *FeatureSchema) cardinality(k int) int {
+	switch fs.Types[k] {
+	case CategoricalFeature:
+		return fs.Cardinalities[k]
+	case BinaryFeature:
+		return 2
+	}
+	return 0
+}
+
+/*
+	A Schematic is a LabeledFeatureSet that knows the types of its dimensions.
+*/
+type Schematic interface {
+	LabeledFeatureSet
+	// Schema returns the types of the dimensions
+	Schema() *FeatureSchema
+}
+
+/*
+	A *MixedNBClassifier is a naive Bayes classifier for features with mixed
+	dimension types: a continuous dimension has a Gaussian likelihood, and a
+	categorical or binary one a categorical likelihood.
+*/
+type MixedNBClassifier struct {
+	// the types of the dimensions
+	Schema *FeatureSchema
+	// Means[label][k] and Vars[label][k] are the mean and the variance of a
+	// continuous dimension k given label
+	Means [][]float64
+	Vars  [][]float64
+	// LogProbs[label][k][c] is the logarithm of the probability of category c
+	// in a categorical or binary dimension k given label
+	LogProbs [][][]float64
+	// the logarithm of prior priorities
+	LogPrior []float64
+}
+
+/*
+	LogPosterior returns the logarithm of the posterior probability, up to a
+	constant, of a feature on a specified label. A categorical or binary
+	dimension whose value is not a valid category is ignored.
+*/
+func (nb *MixedNBClassifier) LogPosterior(label int, x []float64) float64 {
+	logP := nb.LogPrior[label]
+	for k, v := range x {
+		if probs := nb.LogProbs[label][k]; probs != nil {
+			if c, ok := category(v, len(probs)); ok {
+				logP += probs[c]
+			}
+			continue
+		}
+		d := v - nb.Means[label][k]
+		logP -= 0.5 * (math.Log(2*math.Pi*nb.Vars[label][k]) + d*d/nb.Vars[label][k])
+	}
+	return logP
+}
+
+// Implementation of Classifier.Classify
+func (nb *MixedNBClassifier) Classify(x []float64) int {
+	bestLabel, bestLogP := -1, 0.
+	for lbl := range nb.LogPrior {
+		if logP := nb.LogPosterior(lbl, x); bestLabel < 0 || logP > bestLogP {
+			bestLabel, bestLogP = lbl, logP
+		}
+	}
+	return bestLabel
+}
+
+/*
+	MixedNBTrain trains a *MixedNBClassifier from a LabeledFeatureSet with the
+	dimension types of schema, or of lfs.Schema() if schema is nil and lfs is a
+	Schematic. Categories and priors are estimated with Laplace smoothing as in
+	CategoricalNBTrain, and the variances of continuous dimensions are the
+	sample variances.
+
+	An error is returned if no schema is known, a categorical value is invalid,
+	or a continuous dimension of a label has a zero variance.
+*/
+func MixedNBTrain(lfs LabeledFeatureSet, schema *FeatureSchema) (*MixedNBClassifier, error) {
+	if schema == nil {
+		s, ok := lfs.(Schematic)
+		if !ok {
+			return nil, errors.New("no schema given and the feature set is not a Schematic")
+		}
+		schema = s.Schema()
+	}
+
+	lblCnt := lfs.LabelCount()
+	dim := lfs.Dim()
+	if len(schema.Types) != dim {
+		return nil, fmt.Errorf("schema has %d types for dimension %d", len(schema.Types), dim)
+	}
+	for k, t := range schema.Types {
+		if t == CategoricalFeature && (k >= len(schema.Cardinalities) || schema.Cardinalities[k] < 1) {
+			return nil, fmt.Errorf("categorical dimension %d has no positive cardinality", k)
+		}
+	}
+
+	clsfr := &MixedNBClassifier{
+		Schema:   schema,
+		Means:    make([][]float64, lblCnt),
+		Vars:     make([][]float64, lblCnt),
+		LogProbs: make([][][]float64, lblCnt),
+		LogPrior: make([]float64, lblCnt),
+	}
+
+	x := make([]float64, dim)
+
+	total := 0
+	for lbl := 0; lbl < lblCnt; lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		mean := make([]float64, dim)
+		m2 := make([]float64, dim)
+		counts := make([][]float64, dim)
+		for k := range counts {
+			if card := schema.cardinality(k); card > 0 {
+				counts[k] = make([]float64, card)
+			}
+		}
+
+		for i := 0; i < cnt; i++ {
+			lfs.FetchFeature(lbl, i, x)
+			for k, v := range x {
+				if counts[k] != nil {
+					c, ok := category(v, len(counts[k]))
+					if !ok {
+						return nil, fmt.Errorf("label %d, feature %d: value %v of dimension %d is not a category in 0..%d",
+							lbl, i, v, k, len(counts[k])-1)
+					}
+					counts[k][c]++
+					continue
+				}
+				/* Welford's algorithm */
+				delta := v - mean[k]
+				mean[k] += delta / float64(i+1)
+				m2[k] += delta * (v - mean[k])
+			}
+		}
+
+		for k, cs := range counts {
+			if cs == nil {
+				if cnt < 2 || !(m2[k] > 0) {
+					return nil, fmt.Errorf("label %d: continuous dimension %d has zero variance", lbl, k)
+				}
+				m2[k] /= float64(cnt - 1)
+				continue
+			}
+			for c := range cs {
+				cs[c] = math.Log((cs[c] + 1) / float64(cnt+len(cs)))
+			}
+		}
+
+		clsfr.Means[lbl] = mean
+		clsfr.Vars[lbl] = m2
+		clsfr.LogProbs[lbl] = counts
+		clsfr.LogPrior[lbl] = float64(cnt)
+		total += cnt
+	}
+
+	for lbl := range clsfr.LogPrior {
+		clsfr.LogPrior[lbl] = math.Log((clsfr.LogPrior[lbl] + 1) / float64(total+lblCnt))
+	}
+
+	return clsfr, nil
+}
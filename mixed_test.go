@@ -0,0 +1,102 @@
+package pr
+
+import (
+	"math"
+	"testing"
+)
+
+// schemaSet is a Schematic with a fixed schema.
+type schemaSet struct {
+	*SliceFeatureSet
+	schema *FeatureSchema
+}
+
+// Implementation of Schematic.Schema
+func (ss schemaSet) Schema() *FeatureSchema {
+	return ss.schema
+}
+
+// mixedTestSet returns features with a continuous, a categorical and a binary
+// dimension.
+func mixedTestSet() schemaSet {
+	return schemaSet{
+		SliceFeatureSet: &SliceFeatureSet{
+			FeatureDim: 3,
+			Features: [][][]float64{
+				{{0, 0, 1}, {2, 0, 0}, {4, 1, 1}},
+				{{10, 2, 0}, {12, 2, 0}},
+			},
+		},
+		schema: &FeatureSchema{
+			Types:         []int{ContinuousFeature, CategoricalFeature, BinaryFeature},
+			Cardinalities: []int{0, 3, 0},
+		},
+	}
+}
+
+func TestMixedNBTrain(t *testing.T) {
+	nb, err := MixedNBTrain(mixedTestSet(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	/*
+		Label 0 has the continuous mean 2 and variance 8/2, the category counts
+		2, 1, 0 and the binary counts 1, 2; label 1 has the mean 11 and variance
+		2/1, the category counts 0, 0, 2 and the binary counts 2, 0.
+	*/
+	for lbl, want := range []struct {
+		mean, variance float64
+		cat, bin       []float64
+		prior          float64
+	}{
+		{2, 4, []float64{3. / 6, 2. / 6, 1. / 6}, []float64{2. / 5, 3. / 5}, 4. / 7},
+		{11, 2, []float64{1. / 5, 1. / 5, 3. / 5}, []float64{3. / 4, 1. / 4}, 3. / 7},
+	} {
+		if nb.Means[lbl][0] != want.mean || nb.Vars[lbl][0] != want.variance {
+			t.Errorf("label %d: mean %v and variance %v, expected %v and %v",
+				lbl, nb.Means[lbl][0], nb.Vars[lbl][0], want.mean, want.variance)
+		}
+		for k, probs := range [][]float64{nil, want.cat, want.bin} {
+			if probs == nil {
+				continue
+			}
+			logProbs := make([]float64, len(probs))
+			for c, p := range probs {
+				logProbs[c] = math.Log(p)
+			}
+			if !floatsEqual(nb.LogProbs[lbl][k], logProbs, 1e-12) {
+				t.Errorf("label %d, dimension %d: log-probabilities %v, expected %v", lbl, k, nb.LogProbs[lbl][k], logProbs)
+			}
+		}
+		if math.Abs(nb.LogPrior[lbl]-math.Log(want.prior)) > 1e-12 {
+			t.Errorf("label %d: log-prior %v, expected %v", lbl, nb.LogPrior[lbl], math.Log(want.prior))
+		}
+	}
+
+	/* the invalid category 5 is ignored */
+	want := math.Log(4./7) - 0.5*math.Log(2*math.Pi*4) + math.Log(3./5)
+	if logP := nb.LogPosterior(0, []float64{2, 5, 1}); math.Abs(logP-want) > 1e-12 {
+		t.Errorf("log-posterior %v, expected %v", logP, want)
+	}
+	if lbl := nb.Classify([]float64{11, 2, 0}); lbl != 1 {
+		t.Errorf("Classify gives %d, expected 1", lbl)
+	}
+}
+
+func TestMixedNBTrainErrors(t *testing.T) {
+	ss := mixedTestSet()
+	if _, err := MixedNBTrain(ss.SliceFeatureSet, nil); err == nil {
+		t.Error("expected an error without a schema")
+	}
+
+	ss.Features[0][0][1] = 3
+	if _, err := MixedNBTrain(ss.SliceFeatureSet, ss.schema); err == nil {
+		t.Error("expected an error for an invalid category")
+	}
+
+	ss = mixedTestSet()
+	ss.Features[1][1][0] = 10
+	if _, err := MixedNBTrain(ss, nil); err == nil {
+		t.Error("expected an error for a zero variance")
+	}
+}
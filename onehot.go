@@ -0,0 +1,28 @@
+package pr
+
+/*
+	OneHot returns a vector of length labelCount with 1 at label and 0
+	elsewhere. nil is returned if label is not in 0..labelCount-1.
+*/
+func OneHot(label, labelCount int) []float64 {
+	if label < 0 || label >= labelCount {
+		return nil
+	}
+	v := make([]float64, labelCount)
+	v[label] = 1
+	return v
+}
+
+/*
+	OneHotSet returns the one-hot vectors of the labels of all features in
+	lfs, aligned with the rows of FeatureMatrix(lfs).
+*/
+func OneHotSet(lfs LabeledFeatureSet) [][]float64 {
+	lblCnt := lfs.LabelCount()
+	labels := FeatureLabels(lfs)
+	rows := make([][]float64, len(labels))
+	for i, lbl := range labels {
+		rows[i] = OneHot(lbl, lblCnt)
+	}
+	return rows
+}
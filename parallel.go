@@ -0,0 +1,100 @@
+package pr
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+/*
+	AccuracyParallel is like Accuracy but classifies the features in workers
+	goroutines, each with its own feature buffer. c.Classify and
+	lfs.FetchFeature must be safe for concurrent use, which holds for the
+	classifiers and feature sets in this package as long as they are not
+	modified meanwhile. Like Accuracy, it counts rejected features as errors.
+	It falls back to Accuracy if workers <= 1.
+*/
+func AccuracyParallel(c Classifier, lfs LabeledFeatureSet, workers int) float64 {
+	if workers <= 1 {
+		return Accuracy(c, lfs)
+	}
+
+	offsets := labelOffsets(lfs)
+	labels := FeatureLabels(lfs)
+	if len(labels) == 0 {
+		return 0
+	}
+
+	var correct int64
+	var wg sync.WaitGroup
+	chunk := (len(labels) + workers - 1) / workers
+	for start := 0; start < len(labels); start += chunk {
+		end := start + chunk
+		if end > len(labels) {
+			end = len(labels)
+		}
+
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+
+			x := make([]float64, lfs.Dim())
+			cnt := int64(0)
+			for r := start; r < end; r++ {
+				lbl := labels[r]
+				lfs.FetchFeature(lbl, r-offsets[lbl], x)
+				if c.Classify(x) == lbl {
+					cnt++
+				}
+			}
+			atomic.AddInt64(&correct, cnt)
+		}(start, end)
+	}
+	wg.Wait()
+
+	return float64(correct) / float64(len(labels))
+}
+
+/*
+	CrossValidateParallel is like CrossValidate but trains and evaluates the
+	folds in workers goroutines. t.Train must be safe for concurrent use; every
+	call gets its own training and testing views of lfs, so lfs.FetchFeature
+	must be safe for concurrent use as well. The accuracies are ordered by fold
+	and, on failures, the error of the first failed fold is returned.
+*/
+func CrossValidateParallel(t Trainer, lfs LabeledFeatureSet, k int, workers int) (float64, []float64, error) {
+	if k < 2 {
+		return 0, nil, errors.New("k must be at least 2")
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	scores := make([]float64, k)
+	errs := make([]error, k)
+	folds := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for fold := range folds {
+				scores[fold], errs[fold] = crossValidateFold(t, lfs, k, fold, AccuracyMetric)
+			}
+		}()
+	}
+	for fold := 0; fold < k; fold++ {
+		folds <- fold
+	}
+	close(folds)
+	wg.Wait()
+
+	mean := 0.
+	for fold, score := range scores {
+		if errs[fold] != nil {
+			return 0, nil, errs[fold]
+		}
+		mean += score
+	}
+	return mean / float64(k), scores, nil
+}
@@ -0,0 +1,52 @@
+package pr
+
+import (
+	"runtime"
+	"testing"
+)
+
+func BenchmarkAccuracyParallel(b *testing.B) {
+	sfs := randomSet(1, 2000, []float64{0, 0, 0, 0, 0}, []float64{1, 1, 1, 1, 1}, []float64{2, 0, 2, 0, 2})
+	var s StandardScaler
+	s.Fit(sfs)
+	gc, err := (&GaussianTrainer{}).TrainGaussian(s.TransformSet(sfs))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name string
+		lfs  LabeledFeatureSet
+	}{{"transformed", s.TransformSet(sfs)}, {"cached", Cache(s.TransformSet(sfs))}} {
+		b.Run(c.name+"/serial", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Accuracy(gc, c.lfs)
+			}
+		})
+		b.Run(c.name+"/parallel", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				AccuracyParallel(gc, c.lfs, runtime.NumCPU())
+			}
+		})
+	}
+}
+
+func BenchmarkCrossValidateParallel(b *testing.B) {
+	sfs := randomSet(2, 500, []float64{0, 0, 0, 0, 0}, []float64{1, 1, 1, 1, 1}, []float64{2, 0, 2, 0, 2})
+	t := &LDATrainer{}
+
+	b.Run("serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, _, err := CrossValidate(t, sfs, 5); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, _, err := CrossValidateParallel(t, sfs, 5, runtime.NumCPU()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
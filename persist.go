@@ -0,0 +1,126 @@
+package pr
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+/*
+	GaussianFormatVersion is the version of the serialized format written by
+	WriteJSON and WriteGob.
+
+	Version 1 is a bare encoding of a GaussianClassifier with only Means, Precs,
+	LogCoefs and LogPrior. Version 2 wraps the model with a version number and
+	adds Diagonal, Counts, FeatureWeights and Shrinkages. Since JSON has no
+	infinities, a label with a -Inf log prior (e.g. a label without features in
+	RefreshPriorsFromCounts) is written by WriteJSON with a zero LogPrior entry
+	and listed in ImpossibleLabels of the envelope.
+*/
+const GaussianFormatVersion = 2
+
+type gaussianEnvelope struct {
+	Version int
+	Model   *GaussianClassifier
+	// the labels whose log priors are -Inf, JSON only
+	ImpossibleLabels []int `json:",omitempty"`
+}
+
+/*
+	WriteJSON writes gc, with the format version, as JSON to w. An error is
+	returned if a log prior is NaN or +Inf.
+*/
+func (gc *GaussianClassifier) WriteJSON(w io.Writer) error {
+	env := gaussianEnvelope{Version: GaussianFormatVersion, Model: gc}
+	for lbl, p := range gc.LogPrior {
+		if math.IsNaN(p) || math.IsInf(p, 1) {
+			return fmt.Errorf("label %d: log prior is %v", lbl, p)
+		}
+		if math.IsInf(p, -1) {
+			env.ImpossibleLabels = append(env.ImpossibleLabels, lbl)
+		}
+	}
+	if env.ImpossibleLabels != nil {
+		m := *gc
+		m.LogPrior = append([]float64(nil), gc.LogPrior...)
+		for _, lbl := range env.ImpossibleLabels {
+			m.LogPrior[lbl] = 0
+		}
+		env.Model = &m
+	}
+	return json.NewEncoder(w).Encode(env)
+}
+
+/*
+	WriteGob writes gc, with the format version, as gob to w.
+*/
+func (gc *GaussianClassifier) WriteGob(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(gaussianEnvelope{Version: GaussianFormatVersion, Model: gc})
+}
+
+/*
+	LoadGaussian reads a *GaussianClassifier written by WriteJSON or WriteGob,
+	detecting the encoding. Older versions, including a bare encoding of a
+	GaussianClassifier (version 1), are migrated: fields missing in the version
+	get their zero values, which keeps the behavior of the old model. The
+	loaded model is checked as by NewValidatedGaussianClassifier, and the
+	optional per-label and per-dimension fields must have matching lengths.
+*/
+func LoadGaussian(r io.Reader) (*GaussianClassifier, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	isJSON := len(bytes.TrimSpace(data)) > 0 && bytes.TrimSpace(data)[0] == '{'
+	decode := func(v interface{}) error {
+		if isJSON {
+			return json.Unmarshal(data, v)
+		}
+		return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+	}
+
+	var env gaussianEnvelope
+	if err := decode(&env); err != nil || env.Version == 0 {
+		// version 1: a bare GaussianClassifier
+		env = gaussianEnvelope{Version: 1, Model: &GaussianClassifier{}}
+		if err := decode(env.Model); err != nil {
+			return nil, err
+		}
+	}
+
+	if env.Version > GaussianFormatVersion {
+		return nil, fmt.Errorf("unsupported format version %d", env.Version)
+	}
+	gc := env.Model
+	if gc == nil {
+		return nil, errors.New("no model")
+	}
+	for _, lbl := range env.ImpossibleLabels {
+		if lbl < 0 || lbl >= len(gc.LogPrior) {
+			return nil, fmt.Errorf("invalid model: impossible label %d out of range [0, %d)", lbl, len(gc.LogPrior))
+		}
+		gc.LogPrior[lbl] = math.Inf(-1)
+	}
+	if _, err := NewValidatedGaussianClassifier(gc.Means, gc.Precs, gc.LogCoefs); err != nil {
+		return nil, fmt.Errorf("invalid model: %v", err)
+	}
+	lblCnt := len(gc.Means)
+	for _, f := range []struct {
+		name string
+		n    int
+	}{{"log priors", len(gc.LogPrior)}, {"counts", len(gc.Counts)}, {"shrinkages", len(gc.Shrinkages)}} {
+		if f.n != 0 && f.n != lblCnt {
+			return nil, fmt.Errorf("invalid model: %d %s for %d labels", f.n, f.name, lblCnt)
+		}
+	}
+	if gc.FeatureWeights != nil && len(gc.FeatureWeights) != gc.Dim() {
+		return nil, fmt.Errorf("invalid model: %d feature weights for dimension %d", len(gc.FeatureWeights), gc.Dim())
+	}
+
+	return gc, nil
+}
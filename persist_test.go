@@ -0,0 +1,113 @@
+package pr
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestLoadGaussianRejectsInvalid(t *testing.T) {
+	for _, blob := range []string{
+		/* the second mean has a wrong dimension */
+		`{"Version":2,"Model":{"Means":[[0,0],[1]],"Precs":[[-0.5,0,0,-0.5],[-0.5,0,0,-0.5]],"LogCoefs":[-1.8,-1.8]}}`,
+		/* a precision of a wrong size */
+		`{"Version":2,"Model":{"Means":[[0,0],[1,1]],"Precs":[[-0.5,0,0,-0.5],[-0.5]],"LogCoefs":[-1.8,-1.8]}}`,
+		/* a positive precision */
+		`{"Version":2,"Model":{"Means":[[0],[1]],"Precs":[[-0.5],[0.5]],"LogCoefs":[-0.9,-0.9]}}`,
+		/* feature weights of a wrong dimension */
+		`{"Version":2,"Model":{"Means":[[0],[1]],"Precs":[[-0.5],[-0.5]],"LogCoefs":[-0.9,-0.9],"FeatureWeights":[1,1]}}`,
+		/* log priors of a wrong label count */
+		`{"Version":2,"Model":{"Means":[[0],[1]],"Precs":[[-0.5],[-0.5]],"LogCoefs":[-0.9,-0.9],"LogPrior":[-0.7]}}`,
+	} {
+		if _, err := LoadGaussian(strings.NewReader(blob)); err == nil {
+			t.Errorf("expected an error loading %s", blob)
+		}
+	}
+}
+
+func TestLoadGaussianRoundTrip(t *testing.T) {
+	gc, err := (&GaussianTrainer{}).TrainGaussian(randomSet(1, 50, []float64{0, 0}, []float64{2, 3}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, write := range map[string]func(*bytes.Buffer) error{
+		"json": func(b *bytes.Buffer) error { return gc.WriteJSON(b) },
+		"gob":  func(b *bytes.Buffer) error { return gc.WriteGob(b) },
+	} {
+		var b bytes.Buffer
+		if err := write(&b); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadGaussian(&b)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !loaded.Equal(gc, 0) {
+			t.Errorf("%s: loaded model differs", name)
+		}
+	}
+}
+
+func TestLoadGaussianVersion1(t *testing.T) {
+	want := &GaussianClassifier{
+		Means:    [][]float64{{0, 1}, {2, 3}},
+		Precs:    [][]float64{{-0.5, 0.1, 0.1, -0.5}, {-1, 0, 0, -0.25}},
+		LogCoefs: []float64{-1.9, -1.8},
+		LogPrior: []float64{-0.5, -0.9},
+	}
+
+	/* a bare GaussianClassifier with the fields of version 1 only */
+	blob := `{"Means":[[0,1],[2,3]],"Precs":[[-0.5,0.1,0.1,-0.5],[-1,0,0,-0.25]],"LogCoefs":[-1.9,-1.8],"LogPrior":[-0.5,-0.9]}`
+	gc, err := LoadGaussian(strings.NewReader(blob))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gc.Equal(want, 0) {
+		t.Errorf("loaded %v, expected %v", gc, want)
+	}
+	if gc.Diagonal || gc.Counts != nil || gc.FeatureWeights != nil || gc.Shrinkages != nil {
+		t.Errorf("fields added in version 2 are not zero: %+v", gc)
+	}
+
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(want); err != nil {
+		t.Fatal(err)
+	}
+	if gc, err = LoadGaussian(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !gc.Equal(want, 0) {
+		t.Errorf("loaded %v from gob, expected %v", gc, want)
+	}
+}
+
+func TestWriteJSONInfinitePrior(t *testing.T) {
+	gc := &GaussianClassifier{
+		Means:    [][]float64{{0}, {1}, {2}},
+		Precs:    [][]float64{{-0.5}, {-0.5}, {-0.5}},
+		LogCoefs: []float64{-0.9, -0.9, -0.9},
+		LogPrior: []float64{math.Log(0.4), math.Inf(-1), math.Log(0.6)},
+	}
+	var b bytes.Buffer
+	if err := gc.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsInf(gc.LogPrior[1], -1) {
+		t.Errorf("WriteJSON modified the log priors: %v", gc.LogPrior)
+	}
+	loaded, err := LoadGaussian(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsInf(loaded.LogPrior[1], -1) || loaded.LogPrior[0] != gc.LogPrior[0] || loaded.LogPrior[2] != gc.LogPrior[2] {
+		t.Errorf("loaded log priors %v, expected %v", loaded.LogPrior, gc.LogPrior)
+	}
+
+	gc.LogPrior[1] = math.NaN()
+	if err := gc.WriteJSON(&b); err == nil {
+		t.Error("expected an error writing a NaN log prior")
+	}
+}
@@ -0,0 +1,125 @@
+package pr
+
+import (
+	"fmt"
+)
+
+/*
+	A *PipelineClassifier applies a sequence of fitted Transformers to a raw
+	feature before classifying it, so that the transforms used at training time
+	can not be forgotten at prediction time.
+
+	Classify panics on a feature of a wrong dimension, e.g. one already
+	transformed, rather than silently misclassifying it; ClassifyChecked returns
+	an error instead.
+*/
+type PipelineClassifier struct {
+	// the transforms, applied in order
+	Transforms []Transformer
+	// the classifier of the transformed features
+	Classifier Classifier
+	// the dimension of the raw features the pipeline was trained on
+	InputDim int
+}
+
+/*
+	DimCheck returns an error if x does not have the raw dimension of the
+	pipeline, e.g. if x was already transformed.
+*/
+func (pc *PipelineClassifier) DimCheck(x []float64) error {
+	if len(x) != pc.InputDim {
+		return fmt.Errorf("feature has dimension %d, the pipeline expects raw features of dimension %d", len(x), pc.InputDim)
+	}
+	return nil
+}
+
+/*
+	Transform applies all transforms to the raw feature x.
+*/
+func (pc *PipelineClassifier) Transform(x []float64) ([]float64, error) {
+	if err := pc.DimCheck(x); err != nil {
+		return nil, err
+	}
+	for _, t := range pc.Transforms {
+		x = t.Transform(x)
+	}
+	return x, nil
+}
+
+/*
+	MustTransform is like Transform but panics if x has a wrong dimension.
+*/
+func (pc *PipelineClassifier) MustTransform(x []float64) []float64 {
+	y, err := pc.Transform(x)
+	if err != nil {
+		panic(err)
+	}
+	return y
+}
+
+/*
+	ClassifyChecked transforms and classifies the raw feature x, or returns an
+	error if x has a wrong dimension.
+*/
+func (pc *PipelineClassifier) ClassifyChecked(x []float64) (int, error) {
+	y, err := pc.Transform(x)
+	if err != nil {
+		return -1, err
+	}
+	return pc.Classifier.Classify(y), nil
+}
+
+// Implementation of Classifier.Classify
+func (pc *PipelineClassifier) Classify(x []float64) int {
+	return pc.Classifier.Classify(pc.MustTransform(x))
+}
+
+// a Transformer which is fitted on a LabeledFeatureSet, e.g. *StandardScaler
+type fitter interface {
+	Fit(lfs LabeledFeatureSet)
+}
+
+/*
+	A *PipelineTrainer trains a *PipelineClassifier. Every training gets fresh
+	transforms from NewTransforms, so models trained earlier, e.g. of other
+	cross-validation folds, are never changed. Transforms which have a
+	Fit(LabeledFeatureSet) method, e.g. *StandardScaler and *RangeClipper, are
+	fitted in order on the training set transformed by the previous ones;
+	others are used as they are.
+*/
+type PipelineTrainer struct {
+	// returns new transforms, applied in order; it must not return instances
+	// that are fitted, since those would be shared between models
+	NewTransforms func() []Transformer
+	// the trainer of the classifier of the transformed features
+	Trainer Trainer
+}
+
+/*
+	TrainPipeline fits the transforms and trains the classifier on lfs.
+*/
+func (pt *PipelineTrainer) TrainPipeline(lfs LabeledFeatureSet) *PipelineClassifier {
+	pc := &PipelineClassifier{
+		Transforms: pt.NewTransforms(),
+		InputDim:   lfs.Dim(),
+	}
+	for _, t := range pc.Transforms {
+		if f, ok := t.(fitter); ok {
+			f.Fit(lfs)
+		}
+		lfs = t.TransformSet(lfs)
+	}
+	pc.Classifier = pt.Trainer.Train(lfs)
+	if pc.Classifier == nil {
+		return nil
+	}
+	return pc
+}
+
+// Implementation of Trainer.Train
+func (pt *PipelineTrainer) Train(lfs LabeledFeatureSet) Classifier {
+	if pc := pt.TrainPipeline(lfs); pc != nil {
+		return pc
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package pr
+
+import (
+	"testing"
+)
+
+func TestPipelineTrainerKeepsEarlierModels(t *testing.T) {
+	pt := &PipelineTrainer{
+		NewTransforms: func() []Transformer {
+			return []Transformer{&StandardScaler{}}
+		},
+		Trainer: &GaussianTrainer{},
+	}
+
+	first := pt.TrainPipeline(randomSet(1, 50, []float64{0, 1}, []float64{3, 4}))
+	mean := first.Transforms[0].(*StandardScaler).Means[0]
+	pt.TrainPipeline(randomSet(2, 50, []float64{100, 101}, []float64{103, 104}))
+
+	if got := first.Transforms[0].(*StandardScaler).Means[0]; got != mean {
+		t.Errorf("mean of the first model changed from %v to %v", mean, got)
+	}
+}
+
+func TestPipelineClassifierDimCheck(t *testing.T) {
+	pt := &PipelineTrainer{
+		NewTransforms: func() []Transformer {
+			return []Transformer{&StandardScaler{}}
+		},
+		Trainer: &GaussianTrainer{},
+	}
+	pc := pt.TrainPipeline(randomSet(1, 50, []float64{0, 1}, []float64{3, 4}))
+
+	if _, err := pc.ClassifyChecked([]float64{1}); err == nil {
+		t.Error("expected an error for a feature of a wrong dimension")
+	}
+}
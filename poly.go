@@ -0,0 +1,87 @@
+package pr
+
+import (
+	"fmt"
+)
+
+/*
+	MaxPolynomialDegree is the largest degree of PolynomialFeatures, which
+	guards against the combinatorial growth of the output dimension.
+*/
+const MaxPolynomialDegree = 3
+
+/*
+	PolynomialFeatures appends to a feature all products of its components of
+	degree 2 up to Degree, e.g. x0*x0, x0*x1, x1*x1 for a dimension of 2 and a
+	degree of 2.
+
+	The output is the feature itself followed by the terms of each degree in
+	turn, the terms of one degree in lexicographic order of their (ascending)
+	dimension indices. The output dimension is C(Dim+Degree, Degree) - 1.
+*/
+type PolynomialFeatures struct {
+	// the dimension of the input features
+	Dim int
+	// the largest degree of the terms
+	Degree int
+
+	// the dimension indices of every appended term
+	terms [][]int
+}
+
+/*
+	Fit prepares the terms for features of dimension dim up to degree, which
+	must be in [1, MaxPolynomialDegree].
+*/
+func (pf *PolynomialFeatures) Fit(dim, degree int) error {
+	if degree < 1 || degree > MaxPolynomialDegree {
+		return fmt.Errorf("degree %d not in [1, %d]", degree, MaxPolynomialDegree)
+	}
+	pf.Dim, pf.Degree, pf.terms = dim, degree, nil
+
+	/* the terms of degree d extend those of degree d-1 by a non-smaller index */
+	prev := make([][]int, dim)
+	for k := range prev {
+		prev[k] = []int{k}
+	}
+	for d := 2; d <= degree; d++ {
+		var cur [][]int
+		for _, term := range prev {
+			for k := term[len(term)-1]; k < dim; k++ {
+				cur = append(cur, append(append([]int(nil), term...), k))
+			}
+		}
+		pf.terms = append(pf.terms, cur...)
+		prev = cur
+	}
+	return nil
+}
+
+// OutputDim returns the dimension of the transformed features.
+func (pf *PolynomialFeatures) OutputDim() int {
+	return pf.Dim + len(pf.terms)
+}
+
+/*
+	Transform returns x followed by its polynomial terms.
+*/
+func (pf *PolynomialFeatures) Transform(x []float64) []float64 {
+	y := make([]float64, pf.OutputDim())
+	copy(y, x)
+	for i, term := range pf.terms {
+		v := 1.
+		for _, k := range term {
+			v *= x[k]
+		}
+		y[pf.Dim+i] = v
+	}
+	return y
+}
+
+/*
+	TransformSet returns a view of lfs with every feature transformed by
+	Transform.
+*/
+func (pf *PolynomialFeatures) TransformSet(lfs LabeledFeatureSet) LabeledFeatureSet {
+	return newTransformedSet(lfs, pf.OutputDim(), pf.Transform)
+}
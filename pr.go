@@ -29,6 +29,28 @@ type Classifier interface {
 	Classify(x []float64) int
 }
 
+/*
+	A ScoredClassifier is a Classifier that also gives a score for each label.
+	A larger score means the feature is more likely to be of that label.
+*/
+type ScoredClassifier interface {
+	Classifier
+	// Scores fills scores with the score of every label for the feature x.
+	// The length of scores must be the number of labels.
+	Scores(x []float64, scores []float64)
+}
+
+/*
+	A ProbClassifier is a Classifier that also gives the posterior probability
+	of every label.
+*/
+type ProbClassifier interface {
+	Classifier
+	// ClassifyProb returns the posterior probabilities of all labels for the
+	// feature x. The probabilities sum to 1.
+	ClassifyProb(x []float64) []float64
+}
+
 /*
 	A Trainer can train a Classifier given a LabeledFeatureSet.
 */
@@ -36,3 +58,23 @@ type Trainer interface {
 	// Trains trains a Classifier given a LabeledFeatureSet.
 	Train(featureSet LabeledFeatureSet) Classifier
 }
+
+/*
+	A Transformer maps features to (possibly differently dimensioned) features,
+	e.g. a fitted *StandardScaler.
+*/
+type Transformer interface {
+	// Transform returns the transformed feature of x.
+	Transform(x []float64) []float64
+	// TransformSet returns a view of lfs with all features transformed.
+	TransformSet(lfs LabeledFeatureSet) LabeledFeatureSet
+}
+
+/*
+	A DensityModel is a probability density over features, e.g. the Gaussian of
+	one label of a GaussianClassifier. See GenerativeClassifier.
+*/
+type DensityModel interface {
+	// LogDensity returns the logarithm of the density at the feature x.
+	LogDensity(x []float64) float64
+}
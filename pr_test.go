@@ -0,0 +1,28 @@
+package pr
+
+import (
+	"math/rand"
+)
+
+// randomSet returns a *SliceFeatureSet with n features per label, drawn from
+// normal distributions around means with correlated first two dimensions.
+func randomSet(seed int64, n int, means ...[]float64) *SliceFeatureSet {
+	rng := rand.New(rand.NewSource(seed))
+	sfs := &SliceFeatureSet{
+		FeatureDim: len(means[0]),
+		Features:   make([][][]float64, len(means)),
+	}
+	for lbl, mean := range means {
+		for i := 0; i < n; i++ {
+			x := make([]float64, len(mean))
+			for k := range mean {
+				x[k] = mean[k] + rng.NormFloat64()*(1+0.3*float64(k))
+			}
+			if len(mean) > 1 {
+				x[1] += 0.5 * (x[0] - mean[0])
+			}
+			sfs.Features[lbl] = append(sfs.Features[lbl], x)
+		}
+	}
+	return sfs
+}
@@ -0,0 +1,56 @@
+package pr
+
+import (
+	"fmt"
+)
+
+// checkRows returns an error if the rows of X do not all have the same
+// dimension, or, if c has a Dim method (e.g. *GaussianClassifier), the
+// dimension of c.
+func checkRows(c Classifier, X [][]float64) error {
+	if len(X) == 0 {
+		return nil
+	}
+	dim, what := len(X[0]), "row 0"
+	if d, ok := c.(interface {
+		Dim() int
+	}); ok {
+		dim, what = d.Dim(), "the classifier"
+	}
+	for i, x := range X {
+		if len(x) != dim {
+			return fmt.Errorf("row %d has dimension %d, %s has %d", i, len(x), what, dim)
+		}
+	}
+	return nil
+}
+
+/*
+	PredictMatrix classifies every row of the row-major design matrix X with c
+	and returns the labels.
+*/
+func PredictMatrix(c Classifier, X [][]float64) ([]int, error) {
+	if err := checkRows(c, X); err != nil {
+		return nil, err
+	}
+	labels := make([]int, len(X))
+	for i, x := range X {
+		labels[i] = c.Classify(x)
+	}
+	return labels, nil
+}
+
+/*
+	PredictProbaMatrix returns the probabilities of all labels given by pc for
+	every row of the row-major design matrix X.
+*/
+func PredictProbaMatrix(pc ProbClassifier, X [][]float64) ([][]float64, error) {
+	if err := checkRows(pc, X); err != nil {
+		return nil, err
+	}
+	probs := make([][]float64, len(X))
+	for i, x := range X {
+		probs[i] = pc.ClassifyProb(x)
+	}
+	return probs, nil
+}
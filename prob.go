@@ -0,0 +1,48 @@
+package pr
+
+import (
+	"math"
+)
+
+/*
+	logSumExp returns log(sum(exp(logPs))) without overflow/underflow.
+*/
+func logSumExp(logPs []float64) float64 {
+	maxLogP := math.Inf(-1)
+	for _, logP := range logPs {
+		if logP > maxLogP {
+			maxLogP = logP
+		}
+	}
+	if math.IsInf(maxLogP, 0) {
+		return maxLogP
+	}
+
+	sum := 0.
+	for _, logP := range logPs {
+		sum += math.Exp(logP - maxLogP)
+	}
+	return maxLogP + math.Log(sum)
+}
+
+/*
+	normalizeLog subtracts the log-sum-exp of logPs from every element, so that
+	the exponents of the elements sum to 1.
+*/
+func normalizeLog(logPs []float64) {
+	lse := logSumExp(logPs)
+	for i := range logPs {
+		logPs[i] -= lse
+	}
+}
+
+// argmax returns the index of the largest element, the first one on ties.
+func argmax(ps []float64) int {
+	best := -1
+	for i, p := range ps {
+		if best < 0 || p > ps[best] {
+			best = i
+		}
+	}
+	return best
+}
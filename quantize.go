@@ -0,0 +1,156 @@
+package pr
+
+import (
+	"math"
+)
+
+/*
+	QuantizedGaussian is a GaussianClassifier with the means and the precision
+	matrices stored as integers of a given number of bits, each mean vector and
+	each precision matrix with its own scale factor.
+
+	Each stored value is off by at most half its scale factor, i.e. by about
+	max|v|/2^bits relative to the largest absolute value v of its vector or
+	matrix. Values of at most 8 bits are stored as int8 in Means8 and Precs8,
+	wider ones as int16 in Means and Precs. With 16 bits the size of the model
+	is a quarter of the float64 model and the scores are usually
+	indistinguishable; with 8 bits, an eighth, the precisions of
+	weakly correlated dimensions may round to zero, which can change the
+	classification of features near a decision boundary.
+*/
+type QuantizedGaussian struct {
+	// the number of bits of every stored value
+	Bits int
+	// the quantized means, Means[l][k]*MeanScales[l] is the mean, nil if Bits
+	// is at most 8
+	Means      [][]int16
+	MeanScales []float64
+	// the quantized inverse matrices of Sigma times -1/2, Precs[l][i]*PrecScales[l]
+	// is the entry, nil if Bits is at most 8
+	Precs      [][]int16
+	PrecScales []float64
+	// the quantized means and precisions if Bits is at most 8, nil otherwise
+	Means8 [][]int8
+	Precs8 [][]int8
+	// logarithm coefficents
+	LogCoefs []float64
+	// the logarithm of prior probabilities, all zeros for uniform priors
+	LogPrior []float64
+}
+
+// quantize returns v as integers of bits bits and the scale factor.
+func quantize(v []float64, bits int) (q []int16, scale float64) {
+	maxAbs := 0.
+	for _, x := range v {
+		maxAbs = math.Max(maxAbs, math.Abs(x))
+	}
+	q = make([]int16, len(v))
+	if maxAbs == 0 {
+		return q, 1
+	}
+	scale = maxAbs / float64(int(1)<<uint(bits-1)-1)
+	for i, x := range v {
+		q[i] = int16(math.Floor(x/scale + 0.5))
+	}
+	return q, scale
+}
+
+// toInt8 converts values quantized with at most 8 bits into int8.
+func toInt8(q []int16) []int8 {
+	q8 := make([]int8, len(q))
+	for i, v := range q {
+		q8[i] = int8(v)
+	}
+	return q8
+}
+
+/*
+	Quantize returns the quantized model of gc with bits bits per value, which
+	is clamped into [2, 16]. Feature weights, if set, are folded into the
+	precisions.
+*/
+func (gc *GaussianClassifier) Quantize(bits int) QuantizedGaussian {
+	if bits < 2 {
+		bits = 2
+	} else if bits > 16 {
+		bits = 16
+	}
+
+	coeffs := gc.ExportCoefficients()
+	qg := QuantizedGaussian{
+		Bits:       bits,
+		MeanScales: make([]float64, coeffs.LabelCount),
+		PrecScales: make([]float64, coeffs.LabelCount),
+		LogCoefs:   coeffs.LogCoefs,
+		LogPrior:   coeffs.LogPrior,
+	}
+	if bits <= 8 {
+		qg.Means8 = make([][]int8, coeffs.LabelCount)
+		qg.Precs8 = make([][]int8, coeffs.LabelCount)
+	} else {
+		qg.Means = make([][]int16, coeffs.LabelCount)
+		qg.Precs = make([][]int16, coeffs.LabelCount)
+	}
+	for lbl := range qg.MeanScales {
+		mean, meanScale := quantize(coeffs.Means[lbl], bits)
+		prec, precScale := quantize(coeffs.Precs[lbl], bits)
+		qg.MeanScales[lbl], qg.PrecScales[lbl] = meanScale, precScale
+		if bits <= 8 {
+			qg.Means8[lbl], qg.Precs8[lbl] = toInt8(mean), toInt8(prec)
+		} else {
+			qg.Means[lbl], qg.Precs[lbl] = mean, prec
+		}
+	}
+	return qg
+}
+
+/*
+	LogPosterior returns the logarithm of the posterior probability of x on a
+	label, dequantizing the coefficients on the fly.
+*/
+func (qg *QuantizedGaussian) LogPosterior(label int, x []float64) float64 {
+	meanScale, precScale := qg.MeanScales[label], qg.PrecScales[label]
+	dim := len(x)
+
+	diff := make([]float64, dim)
+	logP := 0.
+	if qg.Means8 != nil {
+		mean, prec := qg.Means8[label], qg.Precs8[label]
+		for k := range diff {
+			diff[k] = x[k] - float64(mean[k])*meanScale
+		}
+		for k := range diff {
+			row := prec[k*dim : (k+1)*dim]
+			sum := 0.
+			for l, v := range row {
+				sum += float64(v) * diff[l]
+			}
+			logP += diff[k] * sum
+		}
+	} else {
+		mean, prec := qg.Means[label], qg.Precs[label]
+		for k := range diff {
+			diff[k] = x[k] - float64(mean[k])*meanScale
+		}
+		for k := range diff {
+			row := prec[k*dim : (k+1)*dim]
+			sum := 0.
+			for l, v := range row {
+				sum += float64(v) * diff[l]
+			}
+			logP += diff[k] * sum
+		}
+	}
+	return qg.LogCoefs[label] + logP*precScale + qg.LogPrior[label]
+}
+
+// Implementation of Classifier.Classify
+func (qg *QuantizedGaussian) Classify(x []float64) int {
+	bestLabel, bestLogP := -1, math.Inf(-1)
+	for lbl := range qg.LogCoefs {
+		if logP := qg.LogPosterior(lbl, x); bestLabel < 0 || logP > bestLogP {
+			bestLabel, bestLogP = lbl, logP
+		}
+	}
+	return bestLabel
+}
@@ -0,0 +1,49 @@
+package pr
+
+import (
+	"math"
+	"testing"
+)
+
+func TestQuantizeAccuracy(t *testing.T) {
+	means := [][]float64{{0, 0, 0}, {2, 3, 1}, {-1, 4, 2}}
+	gc, err := (&GaussianTrainer{}).TrainGaussian(randomSet(1, 200, means...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	test := randomSet(2, 500, means...)
+
+	for _, c := range []struct {
+		bits     int
+		minAgree float64
+	}{{16, 0.999}, {8, 0.97}} {
+		qg := gc.Quantize(c.bits)
+		if c.bits <= 8 {
+			if qg.Means8 == nil || qg.Means != nil {
+				t.Errorf("%d bits: expected int8 storage only", c.bits)
+			}
+		} else if qg.Means == nil || qg.Means8 != nil {
+			t.Errorf("%d bits: expected int16 storage only", c.bits)
+		}
+
+		agree, total := 0, 0
+		maxDiff := 0.
+		for _, xs := range test.Features {
+			for _, x := range xs {
+				if qg.Classify(x) == gc.Classify(x) {
+					agree++
+				}
+				total++
+				for lbl := range means {
+					maxDiff = math.Max(maxDiff, math.Abs(qg.LogPosterior(lbl, x)-gc.LogPosterior(lbl, x)))
+				}
+			}
+		}
+		if rate := float64(agree) / float64(total); rate < c.minAgree {
+			t.Errorf("%d bits: quantized model agrees on %.3f of the features, expected at least %.3f", c.bits, rate, c.minAgree)
+		}
+		if c.bits == 16 && maxDiff > 1e-2 {
+			t.Errorf("16 bits: log posteriors differ by up to %g", maxDiff)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package pr
+
+/*
+	RejectLabel is the label returned by a classification with a reject option
+	when it abstains.
+*/
+const RejectLabel = -1
+
+/*
+	RejectByMargin classifies x with pc, or returns RejectLabel if the gap
+	between the top two probabilities is below minMargin. With a single label
+	there is nothing to confuse it with, so it is returned; with no labels,
+	RejectLabel is returned.
+*/
+func RejectByMargin(pc ProbClassifier, x []float64, minMargin float64) int {
+	probs := pc.ClassifyProb(x)
+	switch len(probs) {
+	case 0:
+		return RejectLabel
+	case 1:
+		return 0
+	}
+	best := argmax(probs)
+	second := 0.
+	for lbl, p := range probs {
+		if lbl != best && p > second {
+			second = p
+		}
+	}
+	if probs[best]-second < minMargin {
+		return RejectLabel
+	}
+	return best
+}
@@ -0,0 +1,35 @@
+package pr
+
+import (
+	"testing"
+)
+
+// fixedProbs is a ProbClassifier returning the same probabilities for every
+// feature.
+type fixedProbs []float64
+
+// Implementation of Classifier.Classify
+func (fp fixedProbs) Classify(x []float64) int {
+	return argmax(fp)
+}
+
+// Implementation of ProbClassifier.ClassifyProb
+func (fp fixedProbs) ClassifyProb(x []float64) []float64 {
+	return fp
+}
+
+func TestRejectByMargin(t *testing.T) {
+	for _, c := range []struct {
+		probs fixedProbs
+		want  int
+	}{
+		{fixedProbs{}, RejectLabel},
+		{fixedProbs{1}, 0},
+		{fixedProbs{0.2, 0.7, 0.1}, 1},
+		{fixedProbs{0.45, 0.55}, RejectLabel},
+	} {
+		if got := RejectByMargin(c.probs, []float64{0}, 0.2); got != c.want {
+			t.Errorf("RejectByMargin(%v) = %d, expected %d", c.probs, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package pr
+
+/*
+	SMOTEResampling is the strategy of ResamplingTrainer which oversamples
+	minority labels with SMOTE.
+*/
+const SMOTEResampling = "smote"
+
+/*
+	A *ResamplingTrainer resamples the training set before delegating to
+	Trainer. Since only the set given to Train is resampled, using it in
+	CrossValidate resamples the training folds only and never the test fold.
+*/
+type ResamplingTrainer struct {
+	// the trainer of the resampled set
+	Trainer Trainer
+	// Oversample, Undersample or SMOTEResampling
+	Strategy string
+	// the seed of the random resampling
+	Seed int64
+	// the number of neighbors for SMOTEResampling
+	K int
+}
+
+/*
+	Resample returns the resampled view of lfs, or nil for an unknown strategy.
+*/
+func (rt *ResamplingTrainer) Resample(lfs LabeledFeatureSet) LabeledFeatureSet {
+	if rt.Strategy == SMOTEResampling {
+		return SMOTE(lfs, rt.K, rt.Seed)
+	}
+	return Balance(lfs, rt.Strategy, rt.Seed)
+}
+
+// Implementation of Trainer.Train
+func (rt *ResamplingTrainer) Train(lfs LabeledFeatureSet) Classifier {
+	resampled := rt.Resample(lfs)
+	if resampled == nil {
+		return nil
+	}
+	return rt.Trainer.Train(resampled)
+}
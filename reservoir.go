@@ -0,0 +1,94 @@
+package pr
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+/*
+	A *ReservoirFeatureSet keeps a bounded, uniformly random sample of a stream
+	of labeled features, per label, and exposes it as a LabeledFeatureSet.
+
+	After n features of a label were observed, the retained features of the
+	label are a uniformly random subset of size min(n, capacity) of them (every
+	subset equally likely), whatever the length of the stream. Memory is bounded
+	by capacity features per label.
+*/
+type ReservoirFeatureSet struct {
+	capacity int
+	rng      *rand.Rand
+	dim      int
+	// the retained features of every label
+	features [][][]float64
+	// the number of observed features of every label
+	seen []int
+}
+
+/*
+	ReservoirSample returns an empty *ReservoirFeatureSet keeping at most
+	capacity features per label.
+*/
+func ReservoirSample(capacity int, seed int64) *ReservoirFeatureSet {
+	return &ReservoirFeatureSet{
+		capacity: capacity,
+		rng:      rand.New(rand.NewSource(seed)),
+		dim:      -1,
+	}
+}
+
+/*
+	Observe offers a feature x of the label to the sample (reservoir sampling,
+	Algorithm R). x is copied if retained. The dimension is fixed by the first
+	observed feature; an error is returned for a feature of another dimension
+	or a negative label.
+*/
+func (rs *ReservoirFeatureSet) Observe(label int, x []float64) error {
+	if label < 0 {
+		return fmt.Errorf("negative label %d", label)
+	}
+	if rs.dim < 0 {
+		rs.dim = len(x)
+	} else if len(x) != rs.dim {
+		return fmt.Errorf("feature has dimension %d, expected %d", len(x), rs.dim)
+	}
+	for label >= len(rs.features) {
+		rs.features = append(rs.features, nil)
+		rs.seen = append(rs.seen, 0)
+	}
+
+	rs.seen[label]++
+	if len(rs.features[label]) < rs.capacity {
+		rs.features[label] = append(rs.features[label], append([]float64(nil), x...))
+	} else if j := rs.rng.Intn(rs.seen[label]); j < rs.capacity {
+		copy(rs.features[label][j], x)
+	}
+	return nil
+}
+
+// Seen returns the number of features of the label observed so far.
+func (rs *ReservoirFeatureSet) Seen(label int) int {
+	return rs.seen[label]
+}
+
+// Implementation of LabeledFeatureSet.Dim. It is 0 before any Observe.
+func (rs *ReservoirFeatureSet) Dim() int {
+	if rs.dim < 0 {
+		return 0
+	}
+	return rs.dim
+}
+
+// Implementation of LabeledFeatureSet.LabelCount
+func (rs *ReservoirFeatureSet) LabelCount() int {
+	return len(rs.features)
+}
+
+// Implementation of LabeledFeatureSet.FeatureCount
+func (rs *ReservoirFeatureSet) FeatureCount(label int) int {
+	return len(rs.features[label])
+}
+
+// Implementation of LabeledFeatureSet.FetchFeature
+func (rs *ReservoirFeatureSet) FetchFeature(label, index int, x []float64) {
+	copy(x, rs.features[label][index])
+}
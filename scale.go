@@ -0,0 +1,177 @@
+package pr
+
+import (
+	"math"
+	"sort"
+)
+
+/*
+	A StandardScaler standardizes every dimension of features to zero mean and
+	unit variance.
+
+	It can be fitted on a LabeledFeatureSet with Fit, or on a stream of
+	features by calling Observe for every feature and then FinalizeFit.
+*/
+type StandardScaler struct {
+	// the means of all dimensions
+	Means []float64
+	// the standard deviations of all dimensions
+	Stds []float64
+
+	// running statistics of Observe (Welford's algorithm)
+	count int
+	m2    []float64
+}
+
+/*
+	Observe adds a feature to the running statistics.
+*/
+func (s *StandardScaler) Observe(x []float64) {
+	if s.count == 0 {
+		s.Means = make([]float64, len(x))
+		s.m2 = make([]float64, len(x))
+	}
+	s.count++
+	for k, v := range x {
+		delta := v - s.Means[k]
+		s.Means[k] += delta / float64(s.count)
+		s.m2[k] += delta * (v - s.Means[k])
+	}
+}
+
+/*
+	FinalizeFit computes the standard deviations from the features observed so
+	far and resets the running statistics. Dimensions with zero variance get a
+	standard deviation of 1 so that Transform only centers them.
+*/
+func (s *StandardScaler) FinalizeFit() {
+	s.Stds = make([]float64, len(s.Means))
+	for k := range s.Stds {
+		variance := s.m2[k]
+		if s.count > 1 {
+			variance /= float64(s.count - 1)
+		}
+		if variance > 0 {
+			s.Stds[k] = math.Sqrt(variance)
+		} else {
+			s.Stds[k] = 1
+		}
+	}
+	s.count, s.m2 = 0, nil
+}
+
+/*
+	Fit fits the scaler on all features in lfs.
+*/
+func (s *StandardScaler) Fit(lfs LabeledFeatureSet) {
+	s.count = 0
+	s.Means = make([]float64, lfs.Dim())
+	s.m2 = make([]float64, lfs.Dim())
+
+	x := make([]float64, lfs.Dim())
+	for lbl := 0; lbl < lfs.LabelCount(); lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			lfs.FetchFeature(lbl, i, x)
+			s.Observe(x)
+		}
+	}
+
+	s.FinalizeFit()
+}
+
+/*
+	Transform returns the standardized x.
+*/
+func (s *StandardScaler) Transform(x []float64) []float64 {
+	y := make([]float64, len(x))
+	for k := range x {
+		y[k] = (x[k] - s.Means[k]) / s.Stds[k]
+	}
+	return y
+}
+
+/*
+	TransformSet returns a view of lfs with every feature transformed by
+	Transform.
+*/
+func (s *StandardScaler) TransformSet(lfs LabeledFeatureSet) LabeledFeatureSet {
+	return newTransformedSet(lfs, len(s.Means), s.Transform)
+}
+
+/*
+	A RobustScaler centers every dimension of features at its median and
+	scales it by its interquartile range, which, unlike StandardScaler, is
+	hardly affected by outliers.
+*/
+type RobustScaler struct {
+	// the medians of all dimensions
+	Medians []float64
+	// the interquartile ranges of all dimensions, 1 for a zero range
+	IQRs []float64
+}
+
+// quantile returns the q-quantile of the sorted vs, linearly interpolated.
+func quantile(vs []float64, q float64) float64 {
+	pos := q * float64(len(vs)-1)
+	i := int(pos)
+	if i+1 >= len(vs) {
+		return vs[len(vs)-1]
+	}
+	return vs[i] + (vs[i+1]-vs[i])*(pos-float64(i))
+}
+
+/*
+	Fit computes the medians and the interquartile ranges of all features in
+	lfs. Dimensions with a zero interquartile range, e.g. constant ones, get a
+	range of 1 so that Transform only centers them. An empty lfs gives zero
+	medians.
+*/
+func (s *RobustScaler) Fit(lfs LabeledFeatureSet) {
+	dim := lfs.Dim()
+	values := make([][]float64, dim)
+	x := make([]float64, dim)
+	for lbl := 0; lbl < lfs.LabelCount(); lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			lfs.FetchFeature(lbl, i, x)
+			for k := range x {
+				values[k] = append(values[k], x[k])
+			}
+		}
+	}
+
+	s.Medians = make([]float64, dim)
+	s.IQRs = make([]float64, dim)
+	for k, vs := range values {
+		s.IQRs[k] = 1
+		if len(vs) == 0 {
+			continue
+		}
+		sort.Float64s(vs)
+		s.Medians[k] = quantile(vs, 0.5)
+		if iqr := quantile(vs, 0.75) - quantile(vs, 0.25); iqr > 0 {
+			s.IQRs[k] = iqr
+		}
+	}
+}
+
+/*
+	Transform returns the scaled feature of x, (x - median) / IQR for every
+	dimension.
+*/
+func (s *RobustScaler) Transform(x []float64) []float64 {
+	y := make([]float64, len(x))
+	for k := range x {
+		y[k] = (x[k] - s.Medians[k]) / s.IQRs[k]
+	}
+	return y
+}
+
+/*
+	TransformSet returns a view of lfs with every feature transformed by
+	Transform.
+*/
+func (s *RobustScaler) TransformSet(lfs LabeledFeatureSet) LabeledFeatureSet {
+	return newTransformedSet(lfs, len(s.Medians), s.Transform)
+}
@@ -0,0 +1,69 @@
+package pr
+
+import (
+	"math"
+)
+
+/*
+	PairwiseSeparability returns the symmetric matrix of the Hellinger distances
+	between the Gaussian distributions of every pair of labels. A distance is in
+	[0, 1]: 0 for identical distributions, close to 1 for well separated ones.
+
+	The distance is computed from the Bhattacharyya distance
+
+	  D_B = 1/8*(mu1-mu2)'*inv(Sigma)*(mu1-mu2) + 1/2*log(det(Sigma)/sqrt(det(Sigma1)*det(Sigma2))),
+
+	where Sigma = (Sigma1+Sigma2)/2, as H = sqrt(1 - exp(-D_B)). nil is returned
+	if a covariance matrix can not be reconstructed or is not positive definite.
+*/
+func (gc *GaussianClassifier) PairwiseSeparability() [][]float64 {
+	lblCnt := gc.LabelCount()
+	dim := gc.Dim()
+
+	sigmas := make([][]float64, lblCnt)
+	logDets := make([]float64, lblCnt)
+	for lbl := range sigmas {
+		sigma, err := gc.covariance(lbl)
+		if err != nil {
+			return nil
+		}
+		L, ok := cholesky(sigma, dim)
+		if !ok {
+			return nil
+		}
+		sigmas[lbl], logDets[lbl] = sigma, choleskyLogDet(L, dim)
+	}
+
+	dists := make([][]float64, lblCnt)
+	for i := range dists {
+		dists[i] = make([]float64, lblCnt)
+	}
+
+	sigma := make([]float64, dim*dim)
+	diff := make([]float64, dim)
+	for i := 0; i < lblCnt; i++ {
+		for j := i + 1; j < lblCnt; j++ {
+			for k := range sigma {
+				sigma[k] = (sigmas[i][k] + sigmas[j][k]) / 2.
+			}
+			L, ok := cholesky(sigma, dim)
+			if !ok {
+				return nil
+			}
+			for k := range diff {
+				diff[k] = gc.Means[i][k] - gc.Means[j][k]
+			}
+			/* (mu1-mu2)'*inv(L*L')*(mu1-mu2) = |inv(L)*(mu1-mu2)|^2 */
+			quad := 0.
+			for _, y := range forwardSubst(L, diff, dim) {
+				quad += y * y
+			}
+
+			db := quad/8. + 0.5*(choleskyLogDet(L, dim)-0.5*(logDets[i]+logDets[j]))
+			h := math.Sqrt(math.Max(0, 1-math.Exp(-db)))
+			dists[i][j], dists[j][i] = h, h
+		}
+	}
+
+	return dists
+}
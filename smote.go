@@ -0,0 +1,68 @@
+package pr
+
+import (
+	"math/rand"
+)
+
+/*
+	SMOTE oversamples every minority label up to the largest label count with
+	synthetic features (Synthetic Minority Over-sampling TEchnique). A synthetic
+	feature is a random point on the segment between a random feature of the
+	label and one of its k nearest neighbors of the same label.
+
+	The result holds the original features followed by the synthetic ones.
+	Labels with fewer than two features are not oversampled. k is at least 1.
+	SMOTE assumes continuous features: interpolating categorical ones gives
+	meaningless values.
+*/
+func SMOTE(lfs LabeledFeatureSet, k int, seed int64) *SliceFeatureSet {
+	rng := rand.New(rand.NewSource(seed))
+	if k < 1 {
+		k = 1
+	}
+
+	lblCnt := lfs.LabelCount()
+	maxCnt := 0
+	for lbl := 0; lbl < lblCnt; lbl++ {
+		if cnt := lfs.FeatureCount(lbl); cnt > maxCnt {
+			maxCnt = cnt
+		}
+	}
+
+	dim := lfs.Dim()
+	sfs := &SliceFeatureSet{
+		FeatureDim: dim,
+		Features:   make([][][]float64, lblCnt),
+	}
+	for lbl := range sfs.Features {
+		cnt := lfs.FeatureCount(lbl)
+		features := make([][]float64, cnt, maxCnt)
+		for i := range features {
+			features[i] = make([]float64, dim)
+			lfs.FetchFeature(lbl, i, features[i])
+		}
+
+		if cnt > 1 {
+			knn := &KNNClassifier{Features: features[:cnt], K: k}
+			neighbors := make([][]int, cnt)
+			for len(features) < maxCnt {
+				i := rng.Intn(cnt)
+				if neighbors[i] == nil {
+					neighbors[i] = knn.TrainingNeighbors(i)
+				}
+				nb := features[neighbors[i][rng.Intn(len(neighbors[i]))]]
+
+				u := rng.Float64()
+				x := make([]float64, dim)
+				for d := range x {
+					x[d] = features[i][d] + u*(nb[d]-features[i][d])
+				}
+				features = append(features, x)
+			}
+		}
+
+		sfs.Features[lbl] = features
+	}
+
+	return sfs
+}
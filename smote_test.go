@@ -0,0 +1,65 @@
+package pr
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// onSegment returns true if x is a + u*(b-a) for a u in [0, 1].
+func onSegment(x, a, b []float64) bool {
+	u := -1.
+	for k := range x {
+		if d := b[k] - a[k]; d != 0 {
+			u = (x[k] - a[k]) / d
+			break
+		}
+	}
+	if u < 0 || u > 1 {
+		return false
+	}
+	for k := range x {
+		if math.Abs(a[k]+u*(b[k]-a[k])-x[k]) > 1e-12 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSMOTE(t *testing.T) {
+	minority := [][]float64{{0, 0}, {1, 0}, {10, 10}}
+	sfs := &SliceFeatureSet{
+		FeatureDim: 2,
+		Features: [][][]float64{
+			{{0, 1}, {1, 1}, {2, 1}, {3, 1}, {4, 1}, {5, 1}},
+			minority,
+			{{7, 7}},
+		},
+	}
+	out := SMOTE(sfs, 1, 1)
+
+	if !reflect.DeepEqual(out.Features[0], sfs.Features[0]) {
+		t.Errorf("the majority label changed to %v", out.Features[0])
+	}
+	if !reflect.DeepEqual(out.Features[2], [][]float64{{7, 7}}) {
+		t.Errorf("a single-feature label is oversampled to %v", out.Features[2])
+	}
+
+	got := out.Features[1]
+	if len(got) != 6 || !reflect.DeepEqual(got[:3], minority) {
+		t.Fatalf("minority features %v, expected the 3 originals followed by 3 synthetic ones", got)
+	}
+	/*
+		with k = 1 the nearest neighbor of (0, 0) is (1, 0) and vice versa, and
+		that of (10, 10) is (1, 0)
+	*/
+	for _, x := range got[3:] {
+		if !onSegment(x, minority[0], minority[1]) && !onSegment(x, minority[2], minority[1]) {
+			t.Errorf("synthetic feature %v is not between a feature and its nearest neighbor", x)
+		}
+	}
+
+	if again := SMOTE(sfs, 1, 1); !reflect.DeepEqual(again, out) {
+		t.Error("SMOTE with the same seed gives different features")
+	}
+}
@@ -0,0 +1,123 @@
+package pr
+
+import (
+	"fmt"
+)
+
+/*
+	A SoftLabeledFeatureSet is a LabeledFeatureSet whose features also have a
+	distribution over labels, e.g. smoothed or distilled labels. Its features
+	are the rows of FeatureMatrix; the label a feature is listed under is its
+	hard label.
+*/
+type SoftLabeledFeatureSet interface {
+	LabeledFeatureSet
+	// FeatureSoftLabel returns the probabilities of all labels of the feature
+	// at row index of FeatureMatrix
+	FeatureSoftLabel(index int) []float64
+}
+
+/*
+	SoftSet is a SoftLabeledFeatureSet attaching soft labels to a
+	LabeledFeatureSet; SoftLabels[row] is the distribution over labels of the
+	feature at row of FeatureMatrix.
+*/
+type SoftSet struct {
+	LabeledFeatureSet
+	SoftLabels [][]float64
+}
+
+// Implementation of SoftLabeledFeatureSet.FeatureSoftLabel
+func (ss *SoftSet) FeatureSoftLabel(index int) []float64 {
+	return ss.SoftLabels[index]
+}
+
+/*
+	trainSoft is TrainGaussian on a SoftLabeledFeatureSet: every feature
+	contributes to the mean and the covariance matrix of every label, weighted
+	by its probability of the label. The covariance is normalized by
+	W - V/W, where W is the total weight and V the sum of squared weights of
+	the label, which is cnt-1 for hard labels.
+*/
+func (gt *GaussianTrainer) trainSoft(sfs SoftLabeledFeatureSet) (*GaussianClassifier, error) {
+	lblCnt := sfs.LabelCount()
+	dim := sfs.Dim()
+	clsfr := &GaussianClassifier{
+		Means:    make([][]float64, lblCnt),
+		Precs:    make([][]float64, lblCnt),
+		LogCoefs: make([]float64, lblCnt),
+	}
+
+	rows := FeatureMatrix(sfs)
+	weights := make([][]float64, lblCnt)
+	for lbl := range weights {
+		weights[lbl] = make([]float64, len(rows))
+	}
+	for row := range rows {
+		soft := sfs.FeatureSoftLabel(row)
+		if len(soft) != lblCnt {
+			return nil, fmt.Errorf("row %d has %d soft label probabilities, expected %d", row, len(soft), lblCnt)
+		}
+		for lbl, p := range soft {
+			weights[lbl][row] = p
+		}
+	}
+
+	sigma := make([]float64, dim*dim)
+	for lbl := range clsfr.Means {
+		w, v := 0., 0.
+		mean := make([]float64, dim)
+		for row, x := range rows {
+			p := weights[lbl][row]
+			w += p
+			v += p * p
+			for k := range mean {
+				mean[k] += p * x[k]
+			}
+		}
+		if w <= 0 {
+			return nil, fmt.Errorf("label %d has a total weight of %g", lbl, w)
+		}
+		for k := range mean {
+			mean[k] /= w
+		}
+		norm := w - v/w
+		if !(norm > 0) {
+			return nil, fmt.Errorf("label %d has its weight on a single feature, its covariance matrix is zero", lbl)
+		}
+
+		for i := range sigma {
+			sigma[i] = 0.
+		}
+		for row, x := range rows {
+			p := weights[lbl][row]
+			if p == 0 {
+				continue
+			}
+			for k := 0; k < dim; k++ {
+				dk := p * (x[k] - mean[k])
+				for l := k; l < dim; l++ {
+					sigma[k*dim+l] += dk * (x[l] - mean[l])
+				}
+			}
+		}
+		for i := range sigma {
+			sigma[i] /= norm
+		}
+		symmetrize(sigma, dim)
+		for k := 0; k < dim; k++ {
+			sigma[k*dim+k] += gt.DiagonalEpsilon
+		}
+
+		prec, logCoef, err := gaussianPrec(sigma, dim)
+		if err != nil {
+			return nil, fmt.Errorf("label %d: covariance matrix is singular: %v", lbl, err)
+		}
+
+		clsfr.Means[lbl] = mean
+		clsfr.Precs[lbl] = prec
+		clsfr.LogCoefs[lbl] = logCoef
+	}
+
+	return clsfr, nil
+}
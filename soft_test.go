@@ -0,0 +1,55 @@
+package pr
+
+import (
+	"testing"
+)
+
+// softTestSet returns the rows 0, 2, 4 and 6, the first two of label 0.
+func softTestSet() *SliceFeatureSet {
+	return &SliceFeatureSet{
+		FeatureDim: 1,
+		Features:   [][][]float64{{{0}, {2}}, {{4}, {6}}},
+	}
+}
+
+func TestTrainGaussianHardSoftLabels(t *testing.T) {
+	sfs := softTestSet()
+	ss := &SoftSet{sfs, [][]float64{{1, 0}, {1, 0}, {0, 1}, {0, 1}}}
+	soft, err := (&GaussianTrainer{}).TrainGaussian(ss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hard, err := (&GaussianTrainer{}).TrainGaussian(sfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !soft.Equal(hard, 1e-12) {
+		t.Errorf("soft training on hard labels gives %v, expected %v", soft, hard)
+	}
+}
+
+func TestTrainGaussianSoftLabels(t *testing.T) {
+	ss := &SoftSet{softTestSet(), [][]float64{{1, 0}, {1, 0}, {0.5, 0.5}, {0, 1}}}
+	gc, err := (&GaussianTrainer{}).TrainGaussian(ss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	/*
+		Label 0 has the weights 1, 1, 0.5 and 0: its mean is 4/2.5 = 1.6 and its
+		variance 5.6/(2.5-2.25/2.5) = 3.5. Label 1 has the weights 0, 0, 0.5 and
+		1: its mean is 8/1.5 and its variance (4/3)/(1.5-1.25/1.5) = 2.
+	*/
+	for lbl, want := range []struct{ mean, variance float64 }{{1.6, 3.5}, {8 / 1.5, 2}} {
+		if !floatsEqual(gc.Means[lbl], []float64{want.mean}, 1e-12) {
+			t.Errorf("label %d: mean %v, expected %v", lbl, gc.Means[lbl], want.mean)
+		}
+		if !floatsEqual(gc.Precs[lbl], []float64{-0.5 / want.variance}, 1e-12) {
+			t.Errorf("label %d: precision %v, expected %v", lbl, gc.Precs[lbl], -0.5/want.variance)
+		}
+	}
+
+	ss.SoftLabels[1] = []float64{1}
+	if _, err := (&GaussianTrainer{}).TrainGaussian(ss); err == nil {
+		t.Error("expected an error for a soft label of the wrong length")
+	}
+}
@@ -0,0 +1,174 @@
+package pr
+
+import (
+	"math"
+)
+
+/*
+	SparseFeature is a feature with most of its components being zero. Only the
+	non-zero components are stored.
+*/
+type SparseFeature struct {
+	// the indices of the non-zero components, in 0..Dim-1
+	Indices []int
+	// the values of the non-zero components, aligned with Indices
+	Values []float64
+	// the dimension of the feature
+	Dim int
+}
+
+/*
+	SparseFeatureSet is the sparse counterpart of LabeledFeatureSet.
+	Labels are from 0 to LabelCount-1
+*/
+type SparseFeatureSet interface {
+	// The dimention of the feature
+	Dim() int
+	// The number of labels
+	LabelCount() int
+	// The number of features for a speicified label. Labels are from 0..LabelCount-1
+	FeatureCount(label int) int
+	// Fetch a feature of specified label, and index.
+	FetchSparseFeature(label, index int) SparseFeature
+}
+
+/*
+	A *SparseNaiveBayesClassifier is a Gaussian classifier with a diagonal
+	covariance matrix (i.e. features are assumed to be independent given the
+	label), working on SparseFeatures.
+
+	The log-likelihood of the all-zero feature is precomputed for every label so
+	that classifying a SparseFeature only visits its non-zero components.
+*/
+type SparseNaiveBayesClassifier struct {
+	// the means
+	Means [][]float64
+	// the inverse of the variances times -1/2
+	Precs [][]float64
+	// logarithm coefficents.(log(1/(sqrt((2*Pi)^k*prod(variances))))
+	LogCoefs []float64
+	// the logarithm of the likelyhood of the all-zero feature
+	ZeroLogs []float64
+	// if non-nil, the logarithm of prior priorities
+	LogPrior []float64
+}
+
+/*
+	SetPrior sets the prior probabilities of all labels.
+*/
+func (nb *SparseNaiveBayesClassifier) SetPrior(priors []float64) {
+	if nb.LogPrior == nil {
+		nb.LogPrior = make([]float64, len(priors))
+	}
+	for i := range priors {
+		nb.LogPrior[i] = math.Log(priors[i])
+	}
+}
+
+/*
+	Classify classifies the sparse feature x and returns the label.
+*/
+func (nb *SparseNaiveBayesClassifier) Classify(x SparseFeature) int {
+	bestLogP := 0.
+	bestLabel := -1
+
+	for lbl := range nb.LogCoefs {
+		logP := nb.LogPosterior(lbl, x)
+
+		if bestLabel < 0 || logP > bestLogP {
+			bestLabel, bestLogP = lbl, logP
+		}
+	}
+
+	return bestLabel
+}
+
+/*
+	LogLikelyhood returns the logarithm of the likelyhood of the sparse feature x
+	on a specified label.
+*/
+func (nb *SparseNaiveBayesClassifier) LogLikelyhood(label int, x SparseFeature) float64 {
+	logP := nb.ZeroLogs[label]
+
+	mean := nb.Means[label]
+	prec := nb.Precs[label]
+
+	/* (v - mu)^2 - mu^2 = v * (v - 2 * mu) */
+	for i, k := range x.Indices {
+		v := x.Values[i]
+		logP += v * (v - 2.*mean[k]) * prec[k]
+	}
+
+	return logP
+}
+
+/*
+	LogPosterior returns the logarithm of the posterior probability of a sparse
+	feature on a specified label.
+*/
+func (nb *SparseNaiveBayesClassifier) LogPosterior(label int, x SparseFeature) float64 {
+	if nb.LogPrior == nil {
+		return nb.LogLikelyhood(label, x)
+	}
+	return nb.LogLikelyhood(label, x) + nb.LogPrior[label]
+}
+
+/*
+	SparseNaiveBayesTrain trains a *SparseNaiveBayesClassifier from a
+	SparseFeatureSet. Variances smaller than minVariance are raised to it, which
+	is needed when a dimension is always zero for a label. nil is returned if a
+	resulting variance is not positive.
+*/
+func SparseNaiveBayesTrain(sfs SparseFeatureSet, minVariance float64) *SparseNaiveBayesClassifier {
+	lblCnt := sfs.LabelCount()
+	dim := sfs.Dim()
+	clsfr := &SparseNaiveBayesClassifier{
+		Means:    make([][]float64, lblCnt),
+		Precs:    make([][]float64, lblCnt),
+		LogCoefs: make([]float64, lblCnt),
+		ZeroLogs: make([]float64, lblCnt),
+	}
+
+	for lbl := range clsfr.Means {
+		sum := make([]float64, dim)
+		sqSum := make([]float64, dim)
+
+		cnt := sfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			x := sfs.FetchSparseFeature(lbl, i)
+			for j, k := range x.Indices {
+				v := x.Values[j]
+				sum[k] += v
+				sqSum[k] += v * v
+			}
+		}
+
+		mean := sum
+		prec := make([]float64, dim)
+		logDet, zeroLog := 0., 0.
+		for k := range mean {
+			mean[k] /= float64(cnt)
+			variance := sqSum[k] - float64(cnt)*mean[k]*mean[k]
+			if cnt > 1 {
+				variance /= float64(cnt - 1)
+			}
+			if variance < minVariance {
+				variance = minVariance
+			}
+			if !(variance > 0) {
+				return nil
+			}
+
+			logDet += math.Log(variance)
+			prec[k] = -0.5 / variance
+			zeroLog += mean[k] * mean[k] * prec[k]
+		}
+
+		clsfr.Means[lbl] = mean
+		clsfr.Precs[lbl] = prec
+		clsfr.LogCoefs[lbl] = -0.5 * (math.Log(2.*math.Pi)*float64(dim) + logDet)
+		clsfr.ZeroLogs[lbl] = clsfr.LogCoefs[lbl] + zeroLog
+	}
+
+	return clsfr
+}
@@ -0,0 +1,89 @@
+package pr
+
+import (
+	"math"
+	"testing"
+)
+
+// sliceSparseSet is a SparseFeatureSet of SparseFeatures in memory.
+type sliceSparseSet struct {
+	dim      int
+	features [][]SparseFeature
+}
+
+// Implementation of SparseFeatureSet.Dim
+func (ss *sliceSparseSet) Dim() int {
+	return ss.dim
+}
+
+// Implementation of SparseFeatureSet.LabelCount
+func (ss *sliceSparseSet) LabelCount() int {
+	return len(ss.features)
+}
+
+// Implementation of SparseFeatureSet.FeatureCount
+func (ss *sliceSparseSet) FeatureCount(label int) int {
+	return len(ss.features[label])
+}
+
+// Implementation of SparseFeatureSet.FetchSparseFeature
+func (ss *sliceSparseSet) FetchSparseFeature(label, index int) SparseFeature {
+	return ss.features[label][index]
+}
+
+func TestSparseNaiveBayesTrain(t *testing.T) {
+	sp := func(idx int, v float64) SparseFeature {
+		return SparseFeature{Indices: []int{idx}, Values: []float64{v}, Dim: 3}
+	}
+	ss := &sliceSparseSet{dim: 3, features: [][]SparseFeature{
+		{sp(0, 2), sp(0, 4)},
+		{sp(2, 1), sp(2, 3), sp(1, 6)},
+	}}
+	const minVar = 0.5
+	nb := SparseNaiveBayesTrain(ss, minVar)
+	if nb == nil {
+		t.Fatal("training failed")
+	}
+
+	/*
+		label 0: means (3, 0, 0), variances (2, 0 -> 0.5, 0 -> 0.5)
+		label 1: means (0, 2, 4/3), variances (0 -> 0.5, 12, 7/3)
+	*/
+	wantMeans := [][]float64{{3, 0, 0}, {0, 2, 4. / 3}}
+	wantVars := [][]float64{{2, minVar, minVar}, {minVar, 12, 7. / 3}}
+	for lbl := range wantMeans {
+		if !floatsEqual(nb.Means[lbl], wantMeans[lbl], 1e-12) {
+			t.Errorf("label %d: means %v, expected %v", lbl, nb.Means[lbl], wantMeans[lbl])
+		}
+		for k, v := range wantVars[lbl] {
+			if p := nb.Precs[lbl][k]; math.Abs(p+0.5/v) > 1e-12 {
+				t.Errorf("label %d: precision %d is %v, expected %v", lbl, k, p, -0.5/v)
+			}
+		}
+	}
+
+	/* the sparse likelihood equals the dense diagonal Gaussian density */
+	for _, x := range []SparseFeature{sp(0, 3), sp(1, -1), {Dim: 3}, {Indices: []int{0, 2}, Values: []float64{1, 2}, Dim: 3}} {
+		dense := make([]float64, 3)
+		for i, k := range x.Indices {
+			dense[k] = x.Values[i]
+		}
+		for lbl := range wantMeans {
+			want := 0.
+			for k, v := range wantVars[lbl] {
+				d := dense[k] - wantMeans[lbl][k]
+				want += -0.5*math.Log(2*math.Pi*v) - d*d/(2*v)
+			}
+			if got := nb.LogLikelyhood(lbl, x); math.Abs(got-want) > 1e-12 {
+				t.Errorf("x = %v, label %d: log-likelihood %v, expected %v", dense, lbl, got, want)
+			}
+		}
+	}
+
+	if lbl := nb.Classify(sp(0, 3)); lbl != 0 {
+		t.Errorf("(3, 0, 0) is classified as %d, expected 0", lbl)
+	}
+	if nb := SparseNaiveBayesTrain(ss, 0); nb != nil {
+		t.Error("expected nil for a zero variance without minVariance")
+	}
+}
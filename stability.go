@@ -0,0 +1,34 @@
+package pr
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+	CheckPredictionStability classifies every feature of lfs twice, once fetched
+	into a fresh buffer and once into a reused buffer dirtied with NaNs, and
+	returns an error naming the first feature with different predictions. A
+	mismatch means lfs.FetchFeature does not fully overwrite the buffer, a
+	common bug of custom LabeledFeatureSet implementations.
+*/
+func CheckPredictionStability(c Classifier, lfs LabeledFeatureSet) error {
+	dirty := make([]float64, lfs.Dim())
+	for lbl := 0; lbl < lfs.LabelCount(); lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			fresh := make([]float64, lfs.Dim())
+			lfs.FetchFeature(lbl, i, fresh)
+
+			for k := range dirty {
+				dirty[k] = math.NaN()
+			}
+			lfs.FetchFeature(lbl, i, dirty)
+
+			if p, q := c.Classify(fresh), c.Classify(dirty); p != q {
+				return fmt.Errorf("label %d, feature %d: classified as %d from a fresh buffer but %d from a reused one", lbl, i, p, q)
+			}
+		}
+	}
+	return nil
+}
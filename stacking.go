@@ -0,0 +1,108 @@
+package pr
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+	A StackingClassifier feeds the probabilities given by its base classifiers
+	into a meta classifier. The meta feature is the concatenation of the
+	probabilities of every base classifier without the last label, which is
+	redundant since probabilities sum to 1 (and would make the covariance of a
+	Gaussian meta classifier singular).
+*/
+type StackingClassifier struct {
+	Bases []ProbClassifier
+	Meta  Classifier
+}
+
+// metaFeature concatenates rows of probabilities without their last elements.
+func metaFeature(probs [][]float64) []float64 {
+	var f []float64
+	for _, p := range probs {
+		f = append(f, p[:len(p)-1]...)
+	}
+	return f
+}
+
+// Implementation of Classifier.Classify
+func (sc *StackingClassifier) Classify(x []float64) int {
+	probs := make([][]float64, len(sc.Bases))
+	for i, b := range sc.Bases {
+		probs[i] = b.ClassifyProb(x)
+	}
+	return sc.Meta.Classify(metaFeature(probs))
+}
+
+/*
+	A StackingTrainer trains a StackingClassifier. The meta classifier is
+	trained on out-of-fold probabilities (see CrossValPredictProba) of the base
+	trainers, so it never sees predictions for features a base model was
+	trained on. The base classifiers are then trained on the whole set.
+*/
+type StackingTrainer struct {
+	// trainers of the base classifiers, which must give ProbClassifiers
+	BaseTrainers []Trainer
+	// trainer of the meta classifier
+	MetaTrainer Trainer
+	// the number of folds for the out-of-fold probabilities, 5 if less than 2
+	Folds int
+}
+
+/*
+	TrainStacking trains a *StackingClassifier on lfs.
+*/
+func (st *StackingTrainer) TrainStacking(lfs LabeledFeatureSet) (*StackingClassifier, error) {
+	if len(st.BaseTrainers) == 0 {
+		return nil, errors.New("no base trainers")
+	}
+	folds := st.Folds
+	if folds < 2 {
+		folds = 5
+	}
+
+	// oof[b][row] are the out-of-fold probabilities of base b
+	oof := make([][][]float64, len(st.BaseTrainers))
+	sc := &StackingClassifier{Bases: make([]ProbClassifier, len(st.BaseTrainers))}
+	for b, t := range st.BaseTrainers {
+		probs, err := CrossValPredictProba(t, lfs, folds)
+		if err != nil {
+			return nil, fmt.Errorf("base %d: %v", b, err)
+		}
+		oof[b] = probs
+
+		pc, ok := t.Train(lfs).(ProbClassifier)
+		if !ok || pc == nil {
+			return nil, fmt.Errorf("base %d: training failed or the classifier is not a ProbClassifier", b)
+		}
+		sc.Bases[b] = pc
+	}
+
+	meta := &SliceFeatureSet{Features: make([][][]float64, lfs.LabelCount())}
+	row := make([][]float64, len(oof))
+	for r, lbl := range FeatureLabels(lfs) {
+		for b := range oof {
+			row[b] = oof[b][r]
+		}
+		f := metaFeature(row)
+		meta.FeatureDim = len(f)
+		meta.Features[lbl] = append(meta.Features[lbl], f)
+	}
+
+	sc.Meta = st.MetaTrainer.Train(meta)
+	if sc.Meta == nil {
+		return nil, errors.New("meta training failed")
+	}
+
+	return sc, nil
+}
+
+// Implementation of Trainer.Train. nil is returned if training fails.
+func (st *StackingTrainer) Train(lfs LabeledFeatureSet) Classifier {
+	sc, err := st.TrainStacking(lfs)
+	if err != nil {
+		return nil
+	}
+	return sc
+}
@@ -0,0 +1,59 @@
+package pr
+
+import (
+	"reflect"
+	"testing"
+)
+
+// recordingClassifier records the last classified feature and returns label.
+type recordingClassifier struct {
+	label int
+	last  []float64
+}
+
+// Implementation of Classifier.Classify
+func (rc *recordingClassifier) Classify(x []float64) int {
+	rc.last = append([]float64(nil), x...)
+	return rc.label
+}
+
+func TestStackingClassifierMetaFeature(t *testing.T) {
+	meta := &recordingClassifier{label: 2}
+	sc := &StackingClassifier{
+		Bases: []ProbClassifier{fixedProbs{0.2, 0.5, 0.3}, fixedProbs{0.6, 0.1, 0.3}},
+		Meta:  meta,
+	}
+	if lbl := sc.Classify([]float64{0}); lbl != 2 {
+		t.Errorf("Classify gives %d, expected the meta label 2", lbl)
+	}
+	/* the probabilities of the last label are dropped */
+	if want := []float64{0.2, 0.5, 0.6, 0.1}; !reflect.DeepEqual(meta.last, want) {
+		t.Errorf("meta feature %v, expected %v", meta.last, want)
+	}
+}
+
+func TestStackingTrainer(t *testing.T) {
+	means := [][]float64{{0, 0}, {3, 3}}
+	st := &StackingTrainer{
+		BaseTrainers: []Trainer{&GaussianTrainer{}, &KNNTrainer{K: 5}},
+		MetaTrainer:  &GaussianTrainer{DiagonalEpsilon: 1e-6},
+		Folds:        3,
+	}
+	sc, err := st.TrainStacking(randomSet(1, 60, means...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sc.Bases) != 2 {
+		t.Errorf("%d bases, expected 2", len(sc.Bases))
+	}
+	if gc, ok := sc.Meta.(*GaussianClassifier); !ok || gc.Dim() != 2 {
+		t.Errorf("meta classifier %v, expected a Gaussian of dimension 2", sc.Meta)
+	}
+	if acc := Accuracy(sc, randomSet(2, 100, means...)); acc < 0.85 {
+		t.Errorf("accuracy %v, expected at least 0.85", acc)
+	}
+
+	if _, err := (&StackingTrainer{MetaTrainer: &GaussianTrainer{}}).TrainStacking(randomSet(1, 10, means...)); err == nil {
+		t.Error("expected an error for no base trainers")
+	}
+}
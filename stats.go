@@ -0,0 +1,162 @@
+package pr
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+/*
+	DataStats summarizes a LabeledFeatureSet. See DatasetStats.
+*/
+type DataStats struct {
+	// the dimension of features
+	Dim int
+	// the number of features of every label
+	LabelCounts []int
+	// the smallest label count divided by the largest one, 1 for perfectly
+	// balanced labels
+	BalanceRatio float64
+	// the minimum, the maximum, the mean and the standard deviation of every
+	// dimension over all features
+	Min, Max, Mean, Std []float64
+	// the number of dimensions with zero variance
+	ZeroVarianceDims int
+}
+
+/*
+	DatasetStats computes the summary statistics of lfs, which surfaces common
+	problems breaking the Gaussian trainers: imbalanced labels and constant
+	features.
+*/
+func DatasetStats(lfs LabeledFeatureSet) DataStats {
+	dim := lfs.Dim()
+	st := DataStats{
+		Dim:         dim,
+		LabelCounts: make([]int, lfs.LabelCount()),
+		Min:         make([]float64, dim),
+		Max:         make([]float64, dim),
+		Mean:        make([]float64, dim),
+		Std:         make([]float64, dim),
+	}
+
+	x := make([]float64, dim)
+	m2 := make([]float64, dim)
+	total := 0
+	for lbl := range st.LabelCounts {
+		cnt := lfs.FeatureCount(lbl)
+		st.LabelCounts[lbl] = cnt
+		for i := 0; i < cnt; i++ {
+			lfs.FetchFeature(lbl, i, x)
+			total++
+			for k, v := range x {
+				if total == 1 || v < st.Min[k] {
+					st.Min[k] = v
+				}
+				if total == 1 || v > st.Max[k] {
+					st.Max[k] = v
+				}
+				delta := v - st.Mean[k]
+				st.Mean[k] += delta / float64(total)
+				m2[k] += delta * (v - st.Mean[k])
+			}
+		}
+	}
+
+	for k := range st.Std {
+		if total > 1 {
+			st.Std[k] = math.Sqrt(m2[k] / float64(total-1))
+		}
+		if st.Std[k] == 0 {
+			st.ZeroVarianceDims++
+		}
+	}
+
+	minCnt, maxCnt := 0, 0
+	for lbl, cnt := range st.LabelCounts {
+		if lbl == 0 || cnt < minCnt {
+			minCnt = cnt
+		}
+		if cnt > maxCnt {
+			maxCnt = cnt
+		}
+	}
+	if maxCnt > 0 {
+		st.BalanceRatio = float64(minCnt) / float64(maxCnt)
+	}
+
+	return st
+}
+
+// String returns a readable multi-line summary.
+func (st DataStats) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "dim %d, %d labels, counts %v, balance ratio %.4g, %d zero-variance dims",
+		st.Dim, len(st.LabelCounts), st.LabelCounts, st.BalanceRatio, st.ZeroVarianceDims)
+	for k := 0; k < st.Dim; k++ {
+		fmt.Fprintf(&b, "\n  dim %d: min %.4g, max %.4g, mean %.4g, std %.4g",
+			k, st.Min[k], st.Max[k], st.Mean[k], st.Std[k])
+	}
+
+	return b.String()
+}
+
+/*
+	DetectCollinearity returns the pairs of dimensions, i < j in ascending
+	order, whose absolute correlation over all features of lfs exceeds
+	threshold. Such pairs make the covariance matrices nearly singular, so one
+	dimension of each is a candidate to drop before training. Dimensions with
+	zero variance are not paired.
+*/
+func DetectCollinearity(lfs LabeledFeatureSet, threshold float64) [][2]int {
+	st := DatasetStats(lfs)
+	dim := st.Dim
+
+	cov := make([]float64, dim*dim)
+	x := make([]float64, dim)
+	for lbl := range st.LabelCounts {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			lfs.FetchFeature(lbl, i, x)
+			for k := 0; k < dim; k++ {
+				dk := x[k] - st.Mean[k]
+				for l := k + 1; l < dim; l++ {
+					cov[k*dim+l] += dk * (x[l] - st.Mean[l])
+				}
+			}
+		}
+	}
+
+	total := 0
+	for _, cnt := range st.LabelCounts {
+		total += cnt
+	}
+	var pairs [][2]int
+	for k := 0; k < dim; k++ {
+		for l := k + 1; l < dim; l++ {
+			if st.Std[k] == 0 || st.Std[l] == 0 {
+				continue
+			}
+			corr := cov[k*dim+l] / float64(total-1) / (st.Std[k] * st.Std[l])
+			if math.Abs(corr) > threshold {
+				pairs = append(pairs, [2]int{k, l})
+			}
+		}
+	}
+	return pairs
+}
+
+/*
+	Probe returns the shape of lfs, calling only Dim, LabelCount and
+	FeatureCount, each once per label, and never FetchFeature. It is a cheap
+	alternative to DatasetStats, e.g. to check a custom LabeledFeatureSet.
+*/
+func Probe(lfs LabeledFeatureSet) (dim, labelCount int, perLabelCounts []int) {
+	dim, labelCount = lfs.Dim(), lfs.LabelCount()
+	perLabelCounts = make([]int, labelCount)
+	for lbl := range perLabelCounts {
+		perLabelCounts[lbl] = lfs.FeatureCount(lbl)
+	}
+	return dim, labelCount, perLabelCounts
+}
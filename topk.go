@@ -0,0 +1,66 @@
+package pr
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// boundedHeap keeps the k best of the indices pushed so far, the worst one at
+// the root.
+type boundedHeap struct {
+	indices []int
+	better  func(a, b int) bool
+}
+
+func (h *boundedHeap) Len() int           { return len(h.indices) }
+func (h *boundedHeap) Less(i, j int) bool { return h.better(h.indices[j], h.indices[i]) }
+func (h *boundedHeap) Swap(i, j int)      { h.indices[i], h.indices[j] = h.indices[j], h.indices[i] }
+func (h *boundedHeap) Push(x interface{}) { h.indices = append(h.indices, x.(int)) }
+func (h *boundedHeap) Pop() interface{} {
+	last := h.indices[len(h.indices)-1]
+	h.indices = h.indices[:len(h.indices)-1]
+	return last
+}
+
+/*
+	topK returns the k best of the indices 0..n-1, best first, where better is
+	a strict total order. It takes O(n*log(k)) time.
+*/
+func topK(n, k int, better func(a, b int) bool) []int {
+	if k > n {
+		k = n
+	}
+	if k <= 0 {
+		return []int{}
+	}
+
+	h := &boundedHeap{indices: make([]int, 0, k), better: better}
+	for i := 0; i < n; i++ {
+		if h.Len() < k {
+			heap.Push(h, i)
+		} else if better(i, h.indices[0]) {
+			h.indices[0] = i
+			heap.Fix(h, 0)
+		}
+	}
+
+	sort.Slice(h.indices, func(i, j int) bool {
+		return better(h.indices[i], h.indices[j])
+	})
+	return h.indices
+}
+
+/*
+	TopKIndices returns the indices of the k largest values, or the k smallest
+	if largest is false, ordered from the most extreme. Ties are broken by the
+	smaller index. All indices, sorted, are returned if k > len(values), and
+	none if k <= 0.
+*/
+func TopKIndices(values []float64, k int, largest bool) []int {
+	return topK(len(values), k, func(a, b int) bool {
+		if values[a] != values[b] {
+			return (values[a] > values[b]) == largest
+		}
+		return a < b
+	})
+}
@@ -0,0 +1,39 @@
+package pr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTopKIndices(t *testing.T) {
+	values := []float64{3, 1, 4, 1, 5, 9, 2, 6, 5, 3}
+	for _, c := range []struct {
+		k       int
+		largest bool
+		want    []int
+	}{
+		{3, true, []int{5, 7, 4}},
+		{3, false, []int{1, 3, 6}},
+		/* ties broken by the smaller index */
+		{4, true, []int{5, 7, 4, 8}},
+		{2, false, []int{1, 3}},
+		{5, false, []int{1, 3, 6, 0, 9}},
+		/* k > len returns all, sorted */
+		{20, true, []int{5, 7, 4, 8, 2, 0, 9, 6, 1, 3}},
+		{len(values), false, []int{1, 3, 6, 0, 9, 2, 4, 8, 7, 5}},
+		/* k <= 0 returns none */
+		{0, true, []int{}},
+		{-1, false, []int{}},
+	} {
+		if got := TopKIndices(values, c.k, c.largest); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("TopKIndices(k = %d, largest = %v) = %v, expected %v", c.k, c.largest, got, c.want)
+		}
+	}
+
+	if got := TopKIndices(nil, 3, true); len(got) != 0 {
+		t.Errorf("TopKIndices(nil) = %v, expected none", got)
+	}
+	if got := TopKIndices([]float64{2, 2, 2, 2}, 2, true); !reflect.DeepEqual(got, []int{0, 1}) {
+		t.Errorf("TopKIndices of equal values = %v, expected [0 1]", got)
+	}
+}
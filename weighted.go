@@ -0,0 +1,93 @@
+package pr
+
+import (
+	"math"
+)
+
+/*
+	A WeightedFeatureSet is a LabeledFeatureSet with a weight for every feature,
+	e.g. the sample weights of a boosting round.
+*/
+type WeightedFeatureSet interface {
+	LabeledFeatureSet
+	// FeatureWeight returns the nonnegative weight of the index-th feature of
+	// label
+	FeatureWeight(label, index int) float64
+}
+
+/*
+	WeightedSet is a WeightedFeatureSet attaching weights to a
+	LabeledFeatureSet; Weights[label][index] is the weight of the index-th
+	feature of label.
+*/
+type WeightedSet struct {
+	LabeledFeatureSet
+	Weights [][]float64
+}
+
+// Implementation of WeightedFeatureSet.FeatureWeight
+func (ws *WeightedSet) FeatureWeight(label, index int) float64 {
+	return ws.Weights[label][index]
+}
+
+// featureWeight returns the weight of a feature of lfs, 1 if lfs is not a
+// WeightedFeatureSet.
+func featureWeight(lfs LabeledFeatureSet, label, index int) float64 {
+	if wfs, ok := lfs.(WeightedFeatureSet); ok {
+		return wfs.FeatureWeight(label, index)
+	}
+	return 1
+}
+
+/*
+	WeightedAccuracy returns the weighted fraction of features in lfs that are
+	correctly classified by c, each feature weighted by its weight if lfs is a
+	WeightedFeatureSet, and it equals Accuracy otherwise. As in Accuracy,
+	features classified as RejectLabel count as errors. 0 is returned if the
+	total weight is zero.
+*/
+func WeightedAccuracy(c Classifier, lfs LabeledFeatureSet) float64 {
+	x := make([]float64, lfs.Dim())
+	correct, total := 0., 0.
+	for lbl := 0; lbl < lfs.LabelCount(); lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			w := featureWeight(lfs, lbl, i)
+			lfs.FetchFeature(lbl, i, x)
+			if c.Classify(x) == lbl {
+				correct += w
+			}
+			total += w
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+	return correct / total
+}
+
+/*
+	WeightedCrossEntropy returns the weighted mean cross-entropy of pc on lfs,
+	each feature weighted by its weight if lfs is a WeightedFeatureSet, the
+	plain mean otherwise. Probabilities are clamped below as in Evaluate. 0 is
+	returned if the total weight is zero.
+*/
+func WeightedCrossEntropy(pc ProbClassifier, lfs LabeledFeatureSet) float64 {
+	x := make([]float64, lfs.Dim())
+	ce, total := 0., 0.
+	for lbl := 0; lbl < lfs.LabelCount(); lbl++ {
+		cnt := lfs.FeatureCount(lbl)
+		for i := 0; i < cnt; i++ {
+			w := featureWeight(lfs, lbl, i)
+			lfs.FetchFeature(lbl, i, x)
+			ce -= w * math.Log(math.Max(pc.ClassifyProb(x)[lbl], minProb))
+			total += w
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+	return ce / total
+}
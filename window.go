@@ -0,0 +1,53 @@
+package pr
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+	SlidingWindows returns the windows of window consecutive samples of series,
+	starting at samples 0, stride, 2*stride, ..., as features of dimension
+	window. Windows overlap if stride < window and skip samples if
+	stride > window. The incomplete tail, a last window that would run past the
+	end of series, is dropped. The windows are copies. nil is returned if window
+	or stride is less than 1.
+*/
+func SlidingWindows(series []float64, window, stride int) [][]float64 {
+	if window < 1 || stride < 1 {
+		return nil
+	}
+	var windows [][]float64
+	for start := 0; start+window <= len(series); start += stride {
+		windows = append(windows, append([]float64(nil), series[start:start+window]...))
+	}
+	return windows
+}
+
+/*
+	LabeledSlidingWindows is like SlidingWindows but returns the windows as a
+	*SliceFeatureSet, each window labeled with the label of its last sample.
+	labels[i], the label of series[i], must be in [0, n) where n-1 is the
+	largest label.
+*/
+func LabeledSlidingWindows(series []float64, labels []int, window, stride int) (*SliceFeatureSet, error) {
+	if len(labels) != len(series) {
+		return nil, fmt.Errorf("%d samples but %d labels", len(series), len(labels))
+	}
+	if window < 1 || stride < 1 {
+		return nil, errors.New("window and stride must be at least 1")
+	}
+
+	sfs := &SliceFeatureSet{FeatureDim: window}
+	for start := 0; start+window <= len(series); start += stride {
+		lbl := labels[start+window-1]
+		if lbl < 0 {
+			return nil, fmt.Errorf("sample %d has a negative label %d", start+window-1, lbl)
+		}
+		for lbl >= len(sfs.Features) {
+			sfs.Features = append(sfs.Features, nil)
+		}
+		sfs.Features[lbl] = append(sfs.Features[lbl], append([]float64(nil), series[start:start+window]...))
+	}
+	return sfs, nil
+}